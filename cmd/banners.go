@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shaik80/ODIW/config"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/importer"
+	lp "github.com/shaik80/ODIW/utils/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var importBannersCategory string
+
+// importBannersCmd represents the import-banners command
+var importBannersCmd = &cobra.Command{
+	Use:   "import-banners",
+	Short: "Turn the videos of the banner category into carousel banners",
+	Long: `Creates an enabled banner for every video tagged with the banner category,
+which made up the home page carousel before banners were managed through
+/api/youtube/banners. The banners keep the order the carousel showed and follow
+the existing ones. Videos that already have a banner are skipped, so the command can
+be run again. The category itself is left on the videos.`,
+	Run: ImportBannersFunc,
+}
+
+func ImportBannersFunc(cmd *cobra.Command, args []string) {
+	loadConfig()
+
+	if err := connect.InitOpenSearchClient(config.Cfg); err != nil {
+		lp.Logs.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+
+	videos := repository.NewOpenSearchVideoRepository(config.Cfg.OpenSearch.Timeouts)
+	banners := repository.NewOpenSearchBannerRepository(config.Cfg.OpenSearch.Timeouts)
+	created, err := importer.ImportCategoryBanners(context.Background(), videos, banners, importBannersCategory)
+	fmt.Printf("imported %d banners from category %s\n", created, importBannersCategory)
+	if err != nil {
+		lp.Logs.Error("failed to import banners", "category", importBannersCategory, "error", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(importBannersCmd)
+
+	importBannersCmd.Flags().StringVar(&importBannersCategory, "category", importer.LegacyBannerCategory, "category whose videos become banners")
+}
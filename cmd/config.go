@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shaik80/ODIW/config"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// loadConfig loads the config file selected with --config, with ODIW_* environment
+// overrides, into config.Cfg and sets up the logger. Invalid settings stop the process.
+func loadConfig() {
+	// If configFile is specified, use it; otherwise, use default configuration file
+	configFileName := configFile
+	if configFileName == "" {
+		configFileName = "config.yaml"
+	}
+
+	cfg, err := config.Load(configPrefix + configFileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	config.Cfg = cfg
+
+	// Validate has already checked the level and format
+	level, _ := lp.ParseLevel(cfg.Logging.LogLevel)
+	lp.Logs = lp.New(level, cfg.Logging.Format, os.Stderr)
+}
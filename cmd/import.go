@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/shaik80/ODIW/config"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/importer"
+	"github.com/shaik80/ODIW/internal/metadata"
+	lp "github.com/shaik80/ODIW/utils/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat      string
+	importConcurrency int
+	importReport      bool
+	importAutoCreate  bool
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Bulk import videos from a CSV or NDJSON file",
+	Long: `Imports the videos listed in a file, or stdin when the file is "-".
+
+CSV rows hold a video ID or URL followed by its categories:
+  dQw4w9WgXcQ,fiqh,salah
+NDJSON lines hold one object per video:
+  {"video_id": "https://youtu.be/dQw4w9WgXcQ", "categories": ["fiqh"]}
+
+Videos tagged with categories that do not exist fail unless --auto-create-categories
+is given.`,
+	Args: cobra.ExactArgs(1),
+	Run:  ImportFunc,
+}
+
+func ImportFunc(cmd *cobra.Command, args []string) {
+	loadConfig()
+
+	var input io.Reader = os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			lp.Logs.Error("failed to open import file", "file", args[0], "error", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	format := importFormat
+	if format == "" {
+		format = importer.FormatNDJSON
+		if strings.EqualFold(filepath.Ext(args[0]), ".csv") {
+			format = importer.FormatCSV
+		}
+	}
+
+	items, err := importer.Parse(input, format)
+	if err != nil {
+		lp.Logs.Error("failed to parse import file", "file", args[0], "error", err)
+		os.Exit(1)
+	}
+
+	if err := connect.InitOpenSearchClient(config.Cfg); err != nil {
+		lp.Logs.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	provider, err := metadata.NewProvider(config.Cfg.Metadata)
+	if err != nil {
+		lp.Logs.Error("failed to set up metadata provider", "error", err)
+		os.Exit(1)
+	}
+
+	timeouts := config.Cfg.OpenSearch.Timeouts
+	im := importer.New(provider, repository.NewOpenSearchVideoRepository(timeouts), repository.NewOpenSearchCreatorRepository(timeouts), repository.NewOpenSearchCategoryRepository(timeouts))
+	im.AutoCreateCategories = importAutoCreate
+	im.Concurrency = config.Cfg.Import.Concurrency
+	if importConcurrency > 0 {
+		im.Concurrency = importConcurrency
+	}
+	im.BatchSize = config.Cfg.Import.BatchSize
+
+	// Interrupting the import cancels the pending requests and reports their items as canceled
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	report := im.Run(ctx, items)
+	if importReport {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		for _, item := range report.Items {
+			if item.Status == importer.StatusFailed || item.Status == importer.StatusDuplicate || item.Status == importer.StatusCanceled {
+				fmt.Printf("line %d: %s %s: %s\n", item.Line, item.Input, item.Status, item.Error)
+			}
+		}
+	}
+	fmt.Printf("imported %d of %d videos, %d failed, %d duplicates, %d canceled\n", report.Succeeded, report.Total, report.Failed, report.Duplicates, report.Canceled)
+
+	if report.Failed > 0 || report.Canceled > 0 {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importFormat, "format", "", "input format, csv or ndjson (default from the file extension)")
+	importCmd.Flags().IntVar(&importConcurrency, "concurrency", 0, "number of metadata requests in parallel (default from config)")
+	importCmd.Flags().BoolVar(&importReport, "report", false, "print the full per item report as JSON")
+	importCmd.Flags().BoolVar(&importAutoCreate, "auto-create-categories", false, "create the categories that do not exist yet")
+}
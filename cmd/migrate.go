@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/shaik80/ODIW/config"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/db/opensearch/schema"
+	lp "github.com/shaik80/ODIW/utils/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateDryRun    bool
+	migrateDeleteOld bool
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Create or upgrade the OpenSearch indices",
+	Long: `Applies the versioned index mappings and settings. Each index is served
+through an alias; when a mapping version changes a new index is created, the
+documents are reindexed into it and the alias is swapped atomically.
+
+Writes to an index being migrated are blocked until its alias has moved, so
+inserts, updates and deletes fail for the duration of the copy while reads
+keep working. Run it when the API can afford to reject changes.`,
+	Run: MigrateFunc,
+}
+
+func MigrateFunc(cmd *cobra.Command, args []string) {
+	loadConfig()
+
+	if err := connect.InitOpenSearchClient(config.Cfg); err != nil {
+		lp.Logs.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+
+	opts := schema.MigrateOptions{
+		DryRun:    migrateDryRun,
+		DeleteOld: migrateDeleteOld,
+	}
+	if err := schema.Migrate(context.Background(), connect.Client, opts); err != nil {
+		lp.Logs.Error("failed to migrate indices", "error", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "only print the planned changes")
+	migrateCmd.Flags().BoolVar(&migrateDeleteOld, "delete-old", false, "delete the previous index version after the alias has moved")
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/shaik80/ODIW/config"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/db/opensearch/schema"
+	lp "github.com/shaik80/ODIW/utils/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// reloadSynonymsCmd represents the reload-synonyms command
+var reloadSynonymsCmd = &cobra.Command{
+	Use:   "reload-synonyms",
+	Short: "Reload the search synonyms of the videos index",
+	Long: `Reloads the search analyzer of the videos index after the synonyms file
+(config/analysis/islamic_synonyms.txt on the OpenSearch nodes) has changed.
+New searches use the updated synonyms right away, without reindexing.`,
+	Run: ReloadSynonymsFunc,
+}
+
+func ReloadSynonymsFunc(cmd *cobra.Command, args []string) {
+	loadConfig()
+
+	if err := connect.InitOpenSearchClient(config.Cfg); err != nil {
+		lp.Logs.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+
+	if err := schema.ReloadSynonyms(context.Background(), connect.Client); err != nil {
+		lp.Logs.Error("failed to reload synonyms", "error", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(reloadSynonymsCmd)
+}
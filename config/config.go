@@ -1,19 +1,23 @@
 package config
 
 import (
-	"sync"
-
-	"github.com/spf13/viper"
+	"time"
 )
 
 var Cfg Config
 
 // Config holds the configuration values for the application
 type Config struct {
-	App        AppConfig     `yaml:"app"`
-	OpenSearch OpenSearch    `yaml:"opensearch"`
-	Server     ServerConfig  `yaml:"server"`
-	Logging    LoggingConfig `yaml:"logging"`
+	App        AppConfig      `yaml:"app"`
+	OpenSearch OpenSearch     `yaml:"opensearch"`
+	Server     ServerConfig   `yaml:"server"`
+	Logging    LoggingConfig  `yaml:"logging"`
+	Metadata   MetadataConfig `yaml:"metadata"`
+	Import     ImportConfig   `yaml:"import"`
+	Search     SearchConfig   `yaml:"search"`
+	Refresh    RefreshConfig  `yaml:"refresh"`
+	Auth       AuthConfig     `yaml:"auth"`
+	Metrics    MetricsConfig  `yaml:"metrics"`
 }
 
 // AppConfig holds information about the application
@@ -24,80 +28,163 @@ type AppConfig struct {
 
 // OpenSearch holds the configuration for connecting to OpenSearch
 type OpenSearch struct {
-	Host     string `yaml:"host"`
-	Port     string `yaml:"port"`
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
+	// Addresses lists the node URLs, e.g. https://node1:9200. When empty a single
+	// address is built from Scheme, Host and Port.
+	Addresses []string `yaml:"addresses"`
+	Scheme    string   `yaml:"scheme"`
+	Host      string   `yaml:"host"`
+	Port      string   `yaml:"port"`
+	Username  string   `yaml:"username"`
+	Password  string   `yaml:"password"`
+	// AutoMigrate applies the index migrations when the server starts, before it serves
+	// any request
+	AutoMigrate bool `yaml:"auto_migrate" mapstructure:"auto_migrate"`
+
+	// CACert is the path of a PEM bundle used instead of the system roots
+	CACert string `yaml:"ca_cert" mapstructure:"ca_cert"`
+	// ClientCert and ClientKey are PEM files for mutual TLS
+	ClientCert string `yaml:"client_cert" mapstructure:"client_cert"`
+	ClientKey  string `yaml:"client_key" mapstructure:"client_key"`
+	// InsecureSkipVerify disables certificate verification, for local clusters only
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify" mapstructure:"insecure_skip_verify"`
+
+	// RetryOnStatus lists the response codes retried on another node
+	RetryOnStatus []int `yaml:"retry_on_status" mapstructure:"retry_on_status"`
+	MaxRetries    int   `yaml:"max_retries" mapstructure:"max_retries"`
+	// RetryBackoff is the delay before the first retry; it doubles on every attempt
+	RetryBackoff time.Duration `yaml:"retry_backoff" mapstructure:"retry_backoff"`
+	// RequestTimeout bounds connecting to a node, TLS handshake included. Calls are
+	// bounded by Timeouts and the request deadline instead.
+	RequestTimeout time.Duration `yaml:"request_timeout" mapstructure:"request_timeout"`
+	// StartupTimeout is how long commands wait for the cluster to come up
+	StartupTimeout time.Duration `yaml:"startup_timeout" mapstructure:"startup_timeout"`
+	// Timeouts bounds each repository call, retries included
+	Timeouts OperationTimeouts `yaml:"timeouts"`
+}
+
+// OperationTimeouts holds the deadline of each kind of OpenSearch operation. Zero
+// leaves the call bounded only by the request deadline.
+type OperationTimeouts struct {
+	// Read covers gets, searches and aggregations
+	Read time.Duration `yaml:"read"`
+	// Write covers single document inserts, updates and deletes
+	Write time.Duration `yaml:"write"`
+	// Bulk covers bulk indexing
+	Bulk time.Duration `yaml:"bulk"`
 }
 
 // ServerConfig holds the configuration values for the web server
 type ServerConfig struct {
-	Host string `yaml:"host"`
-	Port string `yaml:"port"`
+	Host         string        `yaml:"host"`
+	Port         string        `yaml:"port"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" mapstructure:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" mapstructure:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" mapstructure:"idle_timeout"`
+	// BodyLimit is the maximum request body size in bytes
+	BodyLimit int `yaml:"body_limit" mapstructure:"body_limit"`
+	// Prefork starts one process per CPU. Migrations and the refresh scheduler
+	// then only run in the parent process.
+	Prefork bool `yaml:"prefork"`
+	// Concurrency is the maximum number of concurrent connections
+	Concurrency int `yaml:"concurrency"`
+	// ShutdownTimeout bounds the time in-flight requests get to finish on SIGINT/SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" mapstructure:"shutdown_timeout"`
+	// RequestTimeout is the deadline of each API request, passed down to OpenSearch and
+	// metadata calls through the request context
+	RequestTimeout time.Duration `yaml:"request_timeout" mapstructure:"request_timeout"`
 }
 
-// LoggingConfig holds the configuration for logging
-type LoggingConfig struct {
-	LogLevel string `yaml:"loglevel"`
+// MetadataConfig selects where video details are fetched from.
+// Provider is one of "downloader" (default), "youtube" or "file".
+type MetadataConfig struct {
+	Provider    string        `yaml:"provider"`
+	BaseURL     string        `yaml:"base_url" mapstructure:"base_url"`
+	APIKey      string        `yaml:"api_key" mapstructure:"api_key"`
+	FixturesDir string        `yaml:"fixtures_dir" mapstructure:"fixtures_dir"`
+	Timeout     time.Duration `yaml:"timeout"`
 }
 
-var (
-	appConfig     Config
-	appConfigOnce sync.Once
-)
-
-// Load initializes the configuration once
-func Load() error {
-	var err error
-	appConfigOnce.Do(func() {
-		err = loadConfig()
-	})
-	return err
+// ImportConfig holds the limits for bulk video imports
+type ImportConfig struct {
+	// Concurrency is the number of metadata requests made in parallel
+	Concurrency int `yaml:"concurrency"`
+	// BatchSize is the number of videos written per bulk request
+	BatchSize int `yaml:"batch_size" mapstructure:"batch_size"`
+	// MaxItems caps the number of entries accepted by the bulk endpoint
+	MaxItems int `yaml:"max_items" mapstructure:"max_items"`
+	// Timeout is the deadline of a bulk import request, in place of server.request_timeout
+	Timeout time.Duration `yaml:"timeout"`
 }
 
-// loadConfig loads the configuration from the file
-func loadConfig() error {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("./config")
-
-	// Set default values
-	viper.SetDefault("app.name", "MyFiberApp")
-	viper.SetDefault("app.description", "A sample Fiber application")
-
-	viper.SetDefault("opensearch.host", "localhost")
-	viper.SetDefault("opensearch.port", "9200")
-	viper.SetDefault("opensearch.username", "admin")
-	viper.SetDefault("opensearch.password", "admin")
-
-	viper.SetDefault("server.host", "localhost")
-	viper.SetDefault("server.port", "8080")
-
-	viper.SetDefault("logging.log_level", "info")
+// SearchConfig tunes how search queries match the indexed text
+type SearchConfig struct {
+	// Fuzziness is the edit distance allowed per query term: AUTO, 0, 1 or 2. AUTO
+	// allows one edit from 3 characters and two from 6. Empty or 0 disables typo tolerance.
+	Fuzziness string `yaml:"fuzziness"`
+	// PrefixLength is the number of leading characters that must match exactly
+	PrefixLength int `yaml:"prefix_length" mapstructure:"prefix_length"`
+	// PhraseBoost weighs titles containing the query as a phrase; zero disables it
+	PhraseBoost float64 `yaml:"phrase_boost" mapstructure:"phrase_boost"`
+	// MaxResultWindow caps from + size of page based requests, like the index setting of
+	// the same name. Deeper results are read with cursors.
+	MaxResultWindow int `yaml:"max_result_window" mapstructure:"max_result_window"`
+	// MaxPageSize caps the size of a page, whether it is selected by number or by cursor
+	MaxPageSize int `yaml:"max_page_size" mapstructure:"max_page_size"`
+	// PointInTimeKeepAlive is how long a point in time requested by a client stays open
+	// between two pages; zero turns point in time requests into live reads
+	PointInTimeKeepAlive time.Duration `yaml:"point_in_time_keep_alive" mapstructure:"point_in_time_keep_alive"`
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return err
-	}
+// RefreshConfig holds the settings of the background metadata refresh
+type RefreshConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	Interval    time.Duration `yaml:"interval"`
+	BatchSize   int           `yaml:"batch_size" mapstructure:"batch_size"`
+	Concurrency int           `yaml:"concurrency"`
+	// MinAge skips videos refreshed more recently than this
+	MinAge time.Duration `yaml:"min_age" mapstructure:"min_age"`
+}
 
-	if err := viper.Unmarshal(&appConfig); err != nil {
-		return err
-	}
+// AuthConfig holds the credentials accepted by the API. Read routes stay public;
+// write routes require a curator or admin role.
+type AuthConfig struct {
+	// Enabled turns authentication on; when off every request is treated as admin
+	Enabled bool           `yaml:"enabled"`
+	APIKeys []APIKeyConfig `yaml:"api_keys" mapstructure:"api_keys"`
+	JWT     JWTConfig      `yaml:"jwt"`
+}
 
-	return nil
+// APIKeyConfig is a static API key and the role it grants
+type APIKeyConfig struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+	Role string `yaml:"role"`
 }
 
-// GetConfig returns the loaded configuration
-func GetConfig() Config {
-	return appConfig
+// JWTConfig holds the settings used to verify bearer tokens.
+// Algorithm is "HS256" (with Secret) or "RS256" (with PublicKeyFile).
+type JWTConfig struct {
+	Algorithm     string `yaml:"algorithm"`
+	Secret        string `yaml:"secret"`
+	PublicKeyFile string `yaml:"public_key_file" mapstructure:"public_key_file"`
+	Issuer        string `yaml:"issuer"`
+	Audience      string `yaml:"audience"`
+	// RoleClaim names the claim holding the role, either a string or a list of strings
+	RoleClaim string        `yaml:"role_claim" mapstructure:"role_claim"`
+	Leeway    time.Duration `yaml:"leeway"`
 }
 
-// GetString gets a string configuration value by key
-func GetString(key string) string {
-	return viper.GetString(key)
+// MetricsConfig controls the Prometheus metrics endpoint
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`
+	// Role is the auth role required to scrape, e.g. "viewer"; empty leaves the endpoint public
+	Role string `yaml:"role"`
 }
 
-// GetInt gets an integer configuration value by key
-func GetInt(key string) int {
-	return viper.GetInt(key)
+// LoggingConfig holds the configuration for logging
+type LoggingConfig struct {
+	LogLevel string `yaml:"loglevel"`
+	// Format is "text" (default) or "json"
+	Format string `yaml:"format"`
 }
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix prefixes the environment variables that override config keys, e.g.
+// ODIW_OPENSEARCH_HOST overrides opensearch.host. ODIW_<KEY>_FILE reads the value
+// from a file instead, e.g. ODIW_OPENSEARCH_PASSWORD_FILE for mounted secrets.
+const EnvPrefix = "ODIW"
+
+// Load reads the YAML file at path, applies the defaults and environment overrides
+// and validates the result
+func Load(path string) (Config, error) {
+	var cfg Config
+
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigType("yaml")
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return cfg, fmt.Errorf("reading config file: %w", err)
+	}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	keys := configKeys(reflect.TypeOf(cfg), "")
+	for _, key := range keys {
+		// AutomaticEnv only covers keys viper already knows about
+		if err := v.BindEnv(key); err != nil {
+			return cfg, err
+		}
+	}
+	if err := readSecretFiles(v, keys); err != nil {
+		return cfg, err
+	}
+
+	if err := v.Unmarshal(&cfg); err != nil {
+		return cfg, fmt.Errorf("decoding config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// EnvName returns the environment variable overriding key
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// readSecretFiles sets each key whose <ENV>_FILE variable is set to the file content
+func readSecretFiles(v *viper.Viper, keys []string) error {
+	for _, key := range keys {
+		name := EnvName(key) + "_FILE"
+		path, ok := os.LookupEnv(name)
+		if !ok || path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+		v.Set(key, strings.TrimRight(string(data), "\r\n"))
+	}
+	return nil
+}
+
+// configKeys lists the dotted keys of the scalar and list fields of t. Lists are given
+// comma separated in the environment; lists of structs such as auth.api_keys can only
+// be set in the config file.
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		key := prefix + name
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, configKeys(field.Type, key+".")...)
+		case reflect.Slice:
+			if field.Type.Elem().Kind() != reflect.Struct {
+				keys = append(keys, key)
+			}
+		case reflect.Map:
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// setDefaults holds the default of every key that is not required
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("opensearch.scheme", "https")
+	v.SetDefault("opensearch.host", "localhost")
+	v.SetDefault("opensearch.port", "9200")
+	v.SetDefault("opensearch.retry_on_status", []int{502, 503, 504})
+	v.SetDefault("opensearch.max_retries", 3)
+	v.SetDefault("opensearch.retry_backoff", "100ms")
+	v.SetDefault("opensearch.request_timeout", "30s")
+	v.SetDefault("opensearch.startup_timeout", "60s")
+	v.SetDefault("opensearch.timeouts.read", "10s")
+	v.SetDefault("opensearch.timeouts.write", "10s")
+	v.SetDefault("opensearch.timeouts.bulk", "60s")
+	v.SetDefault("opensearch.auto_migrate", true)
+
+	v.SetDefault("server.port", "8080")
+	v.SetDefault("server.read_timeout", "10s")
+	v.SetDefault("server.write_timeout", "30s")
+	v.SetDefault("server.idle_timeout", "60s")
+	v.SetDefault("server.body_limit", 4*1024*1024)
+	v.SetDefault("server.concurrency", 256*1024)
+	v.SetDefault("server.shutdown_timeout", "15s")
+	v.SetDefault("server.request_timeout", "60s")
+
+	v.SetDefault("logging.loglevel", "info")
+	v.SetDefault("logging.format", "text")
+
+	v.SetDefault("metadata.provider", "downloader")
+	v.SetDefault("metadata.timeout", "15s")
+
+	v.SetDefault("import.concurrency", 4)
+	v.SetDefault("import.batch_size", 500)
+	v.SetDefault("import.max_items", 1000)
+	v.SetDefault("import.timeout", "10m")
+
+	v.SetDefault("search.fuzziness", "AUTO")
+	v.SetDefault("search.prefix_length", 1)
+	v.SetDefault("search.phrase_boost", 2)
+	v.SetDefault("search.max_result_window", 10000)
+	v.SetDefault("search.max_page_size", 100)
+	v.SetDefault("search.point_in_time_keep_alive", "1m")
+
+	v.SetDefault("refresh.interval", "10m")
+	v.SetDefault("refresh.batch_size", 50)
+	v.SetDefault("refresh.concurrency", 2)
+	v.SetDefault("refresh.min_age", "24h")
+
+	v.SetDefault("auth.enabled", true)
+	v.SetDefault("auth.jwt.role_claim", "role")
+	v.SetDefault("auth.jwt.leeway", "30s")
+
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.path", "/metrics")
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file holding content and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "opensearch:\n  host: \"search.local\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OpenSearch.Host != "search.local" || cfg.OpenSearch.Port != "9200" || cfg.Server.Port != "8080" {
+		t.Errorf("host %q, port %q, server port %q", cfg.OpenSearch.Host, cfg.OpenSearch.Port, cfg.Server.Port)
+	}
+	if cfg.OpenSearch.Timeouts.Bulk != time.Minute || cfg.Search.MaxResultWindow != 10000 || !cfg.Auth.Enabled {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+}
+
+func TestLoadShippedConfigs(t *testing.T) {
+	for _, name := range []string{"config.yaml", "config.debug.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(name); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLoadEnvOverrides(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secret, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		check func(t *testing.T, cfg Config)
+	}{
+		{
+			name: "scalar", env: map[string]string{"ODIW_OPENSEARCH_HOST": "search.internal", "ODIW_SERVER_PORT": "9090"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.OpenSearch.Host != "search.internal" || cfg.Server.Port != "9090" {
+					t.Errorf("host %q, port %q", cfg.OpenSearch.Host, cfg.Server.Port)
+				}
+			},
+		},
+		{
+			name: "key missing from the file", env: map[string]string{"ODIW_METADATA_API_KEY": "key"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.Metadata.APIKey != "key" {
+					t.Errorf("api key %q", cfg.Metadata.APIKey)
+				}
+			},
+		},
+		{
+			name: "nested duration", env: map[string]string{"ODIW_OPENSEARCH_TIMEOUTS_READ": "2s"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.OpenSearch.Timeouts.Read != 2*time.Second {
+					t.Errorf("read timeout %s", cfg.OpenSearch.Timeouts.Read)
+				}
+			},
+		},
+		{
+			name: "comma separated list", env: map[string]string{"ODIW_OPENSEARCH_ADDRESSES": "https://node1:9200,https://node2:9200"},
+			check: func(t *testing.T, cfg Config) {
+				if want := []string{"https://node1:9200", "https://node2:9200"}; !reflect.DeepEqual(cfg.OpenSearch.Addresses, want) {
+					t.Errorf("addresses %q, want %q", cfg.OpenSearch.Addresses, want)
+				}
+			},
+		},
+		{
+			name: "secret file", env: map[string]string{"ODIW_OPENSEARCH_PASSWORD": "from-env", "ODIW_OPENSEARCH_PASSWORD_FILE": secret},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.OpenSearch.Password != "from-file" {
+					t.Errorf("password %q, want the trimmed file content", cfg.OpenSearch.Password)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			cfg, err := Load(writeConfig(t, "opensearch:\n  host: \"search.local\"\n  password: \"from-yaml\"\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		path    string
+		wantErr string
+	}{
+		{name: "missing file", path: filepath.Join(t.TempDir(), "missing.yaml"), wantErr: "reading config file"},
+		{name: "missing secret file", env: map[string]string{"ODIW_OPENSEARCH_PASSWORD_FILE": filepath.Join(t.TempDir(), "missing")}, wantErr: "ODIW_OPENSEARCH_PASSWORD_FILE"},
+		{name: "invalid override", env: map[string]string{"ODIW_SERVER_PORT": "70000"}, wantErr: "server.port"},
+		{name: "malformed duration", env: map[string]string{"ODIW_SERVER_READ_TIMEOUT": "soon"}, wantErr: "decoding config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			path := tt.path
+			if path == "" {
+				path = writeConfig(t, "opensearch:\n  host: \"search.local\"\n")
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
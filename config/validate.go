@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*$`)
+
+// Validate reports every invalid setting at once
+func (c Config) Validate() error {
+	var problems []error
+	check := func(err error) {
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	check(validatePort("server.port", c.Server.Port))
+	if len(c.OpenSearch.Addresses) > 0 {
+		for _, address := range c.OpenSearch.Addresses {
+			check(validateAddress("opensearch.addresses", address))
+		}
+	} else {
+		check(validateHost("opensearch.host", c.OpenSearch.Host))
+		check(validatePort("opensearch.port", c.OpenSearch.Port))
+	}
+	switch strings.ToLower(c.OpenSearch.Scheme) {
+	case "", "http", "https":
+	default:
+		check(fmt.Errorf("opensearch.scheme %q is invalid, use http or https", c.OpenSearch.Scheme))
+	}
+	if (c.OpenSearch.ClientCert == "") != (c.OpenSearch.ClientKey == "") {
+		check(errors.New("opensearch.client_cert and opensearch.client_key must be set together"))
+	}
+	if c.OpenSearch.MaxRetries < 0 {
+		check(errors.New("opensearch.max_retries must not be negative"))
+	}
+
+	switch strings.ToLower(c.Logging.LogLevel) {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		check(fmt.Errorf("logging.loglevel %q is invalid, use debug, info, warn or error", c.Logging.LogLevel))
+	}
+	switch strings.ToLower(c.Logging.Format) {
+	case "", "text", "json":
+	default:
+		check(fmt.Errorf("logging.format %q is invalid, use text or json", c.Logging.Format))
+	}
+
+	switch strings.ToLower(c.Metadata.Provider) {
+	case "", "downloader", "youtube", "file":
+	default:
+		check(fmt.Errorf("metadata.provider %q is invalid, use downloader, youtube or file", c.Metadata.Provider))
+	}
+
+	switch strings.ToUpper(c.Search.Fuzziness) {
+	case "", "AUTO", "0", "1", "2":
+	default:
+		check(fmt.Errorf("search.fuzziness %q is invalid, use AUTO, 0, 1 or 2", c.Search.Fuzziness))
+	}
+	if c.Search.PrefixLength < 0 {
+		check(errors.New("search.prefix_length must not be negative"))
+	}
+	if c.Search.PhraseBoost < 0 {
+		check(errors.New("search.phrase_boost must not be negative"))
+	}
+	if c.Search.MaxResultWindow <= 0 {
+		check(errors.New("search.max_result_window must be positive"))
+	}
+	if c.Search.MaxPageSize <= 0 || c.Search.MaxPageSize > c.Search.MaxResultWindow {
+		check(errors.New("search.max_page_size must be positive and at most search.max_result_window"))
+	}
+
+	for key, value := range map[string]time.Duration{
+		"opensearch.retry_backoff":        c.OpenSearch.RetryBackoff,
+		"opensearch.request_timeout":      c.OpenSearch.RequestTimeout,
+		"opensearch.startup_timeout":      c.OpenSearch.StartupTimeout,
+		"opensearch.timeouts.read":        c.OpenSearch.Timeouts.Read,
+		"opensearch.timeouts.write":       c.OpenSearch.Timeouts.Write,
+		"opensearch.timeouts.bulk":        c.OpenSearch.Timeouts.Bulk,
+		"server.read_timeout":             c.Server.ReadTimeout,
+		"server.write_timeout":            c.Server.WriteTimeout,
+		"server.idle_timeout":             c.Server.IdleTimeout,
+		"server.shutdown_timeout":         c.Server.ShutdownTimeout,
+		"server.request_timeout":          c.Server.RequestTimeout,
+		"metadata.timeout":                c.Metadata.Timeout,
+		"import.timeout":                  c.Import.Timeout,
+		"refresh.interval":                c.Refresh.Interval,
+		"refresh.min_age":                 c.Refresh.MinAge,
+		"search.point_in_time_keep_alive": c.Search.PointInTimeKeepAlive,
+	} {
+		if value < 0 {
+			check(fmt.Errorf("%s must not be negative", key))
+		}
+	}
+	if c.Server.BodyLimit < 0 {
+		check(errors.New("server.body_limit must not be negative"))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config:\n%w", errors.Join(problems...))
+	}
+	return nil
+}
+
+// validatePort requires a port number between 1 and 65535
+func validatePort(key, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required (set it in the config file or %s)", key, EnvName(key))
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("%s %q is not a port number between 1 and 65535", key, value)
+	}
+	return nil
+}
+
+// validateHost requires a bare host name or IP address, without scheme or port
+func validateHost(key, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required (set it in the config file or %s)", key, EnvName(key))
+	}
+	if net.ParseIP(value) != nil || hostnamePattern.MatchString(value) {
+		return nil
+	}
+	return fmt.Errorf("%s %q is not a host name or IP address; leave out the scheme, port and path", key, value)
+}
+
+// validateAddress requires an http or https URL with a host
+func validateAddress(key, value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%s entry %q is not an http or https URL such as https://node1:9200", key, value)
+	}
+	return validateHost(key, u.Hostname())
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig returns a config that passes validation
+func validConfig() Config {
+	return Config{
+		OpenSearch: OpenSearch{Scheme: "https", Host: "localhost", Port: "9200"},
+		Server:     ServerConfig{Port: "8080"},
+		Search:     SearchConfig{Fuzziness: "AUTO", MaxResultWindow: 10000, MaxPageSize: 100},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(cfg *Config)
+		// want lists the keys the error must mention, none for a valid config
+		want []string
+	}{
+		{name: "valid", change: func(cfg *Config) {}},
+		{name: "IP host", change: func(cfg *Config) { cfg.OpenSearch.Host = "10.0.0.5" }},
+		{name: "addresses replace host and port", change: func(cfg *Config) {
+			cfg.OpenSearch.Host, cfg.OpenSearch.Port = "", ""
+			cfg.OpenSearch.Addresses = []string{"https://node1:9200", "http://10.0.0.6:9200"}
+		}},
+		{name: "missing server port", change: func(cfg *Config) { cfg.Server.Port = "" }, want: []string{"server.port is required", "ODIW_SERVER_PORT"}},
+		{name: "port out of range", change: func(cfg *Config) { cfg.OpenSearch.Port = "65536" }, want: []string{"opensearch.port"}},
+		{name: "host with scheme", change: func(cfg *Config) { cfg.OpenSearch.Host = "https://localhost" }, want: []string{"opensearch.host"}},
+		{name: "address without scheme", change: func(cfg *Config) { cfg.OpenSearch.Addresses = []string{"node1:9200"} }, want: []string{"opensearch.addresses"}},
+		{name: "unknown scheme", change: func(cfg *Config) { cfg.OpenSearch.Scheme = "ftp" }, want: []string{"opensearch.scheme"}},
+		{name: "client cert without key", change: func(cfg *Config) { cfg.OpenSearch.ClientCert = "cert.pem" }, want: []string{"opensearch.client_key"}},
+		{name: "negative retries", change: func(cfg *Config) { cfg.OpenSearch.MaxRetries = -1 }, want: []string{"opensearch.max_retries"}},
+		{name: "unknown log level", change: func(cfg *Config) { cfg.Logging.LogLevel = "trace" }, want: []string{"logging.loglevel"}},
+		{name: "unknown log format", change: func(cfg *Config) { cfg.Logging.Format = "xml" }, want: []string{"logging.format"}},
+		{name: "unknown provider", change: func(cfg *Config) { cfg.Metadata.Provider = "vimeo" }, want: []string{"metadata.provider"}},
+		{name: "unknown fuzziness", change: func(cfg *Config) { cfg.Search.Fuzziness = "3" }, want: []string{"search.fuzziness"}},
+		{name: "no result window", change: func(cfg *Config) { cfg.Search.MaxResultWindow = 0 }, want: []string{"search.max_result_window"}},
+		{name: "page size over the window", change: func(cfg *Config) { cfg.Search.MaxPageSize = 20000 }, want: []string{"search.max_page_size"}},
+		{name: "negative duration", change: func(cfg *Config) { cfg.OpenSearch.Timeouts.Bulk = -time.Second }, want: []string{"opensearch.timeouts.bulk"}},
+		{name: "every problem at once", change: func(cfg *Config) {
+			cfg.Server.Port = "http"
+			cfg.Logging.Format = "xml"
+			cfg.Server.BodyLimit = -1
+		}, want: []string{"server.port", "logging.format", "server.body_limit"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.change(&cfg)
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate succeeded, want an error about %v", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shaik80/ODIW/config"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+// jwtVerifier checks the signature and registered claims of compact JWTs
+type jwtVerifier struct {
+	key       interface{}
+	parser    *jwt.Parser
+	roleClaim string
+}
+
+// newJWTVerifier returns nil when no JWT key is configured
+func newJWTVerifier(cfg config.JWTConfig) (*jwtVerifier, error) {
+	if cfg.Secret == "" && cfg.PublicKeyFile == "" {
+		return nil, nil
+	}
+
+	v := &jwtVerifier{roleClaim: cfg.RoleClaim}
+	if v.roleClaim == "" {
+		v.roleClaim = "role"
+	}
+
+	algorithm := strings.ToUpper(cfg.Algorithm)
+	switch algorithm {
+	case AlgHS256:
+		if cfg.Secret == "" {
+			return nil, errors.New("auth.jwt.secret is required for HS256")
+		}
+		v.key = []byte(cfg.Secret)
+	case AlgRS256:
+		if cfg.PublicKeyFile == "" {
+			return nil, errors.New("auth.jwt.public_key_file is required for RS256")
+		}
+		key, err := loadRSAPublicKey(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		v.key = key
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q, use HS256 or RS256", cfg.Algorithm)
+	}
+
+	// The algorithm is fixed by configuration so a token cannot pick a weaker one, and
+	// tokens must expire
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{algorithm}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(options...)
+	return v, nil
+}
+
+// loadRSAPublicKey reads a PEM encoded PKIX or PKCS#1 public key, or a certificate
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWT public key: %w", err)
+	}
+	key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing JWT public key in %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// verify checks the token and returns its subject and role
+func (v *jwtVerifier) verify(token string) (string, Role, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return v.key, nil
+	}); err != nil {
+		return "", "", err
+	}
+
+	role, ok := highest(stringsClaim(claims[v.roleClaim]))
+	if !ok {
+		return "", "", fmt.Errorf("token has no known role in claim %q", v.roleClaim)
+	}
+	subject, _ := claims["sub"].(string)
+	return subject, role, nil
+}
+
+// stringsClaim reads a claim that is either a string or a list of strings
+func stringsClaim(value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
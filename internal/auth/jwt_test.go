@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shaik80/ODIW/config"
+)
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJWTVerifier(t *testing.T) {
+	const secret = "test-secret"
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	publicKeyFile := filepath.Join(t.TempDir(), "public.pem")
+	if err := os.WriteFile(publicKeyFile, publicPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hs256 := config.JWTConfig{Algorithm: "HS256", Secret: secret, Issuer: "odiw-auth", Audience: "odiw"}
+	rs256 := config.JWTConfig{Algorithm: "RS256", PublicKeyFile: publicKeyFile}
+	now := time.Now()
+	valid := func(extra jwt.MapClaims) jwt.MapClaims {
+		claims := jwt.MapClaims{"sub": "jane", "role": "curator", "iss": "odiw-auth", "aud": "odiw", "exp": now.Add(time.Hour).Unix()}
+		for name, value := range extra {
+			if value == nil {
+				delete(claims, name)
+			} else {
+				claims[name] = value
+			}
+		}
+		return claims
+	}
+	withLeeway := hs256
+	withLeeway.Leeway = time.Minute
+
+	tests := []struct {
+		name    string
+		cfg     config.JWTConfig
+		token   string
+		wantErr bool
+		want    Role
+	}{
+		{name: "valid", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(nil)), want: RoleCurator},
+		{name: "highest role of a list", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"role": []string{"viewer", "admin", "owner"}})), want: RoleAdmin},
+		{name: "audience list", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"aud": []string{"other", "odiw"}})), want: RoleCurator},
+		{name: "bad signature", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte("other-secret"), valid(nil)), wantErr: true},
+		{name: "tampered claims", cfg: hs256, token: func() string {
+			token := sign(t, jwt.SigningMethodHS256, []byte(secret), valid(nil))
+			admin := sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"role": "admin"}))
+			return admin[:len(admin)-43] + token[len(token)-43:]
+		}(), wantErr: true},
+		{name: "malformed", cfg: hs256, token: "not.a-token", wantErr: true},
+		{name: "alg none", cfg: hs256, token: sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid(nil)), wantErr: true},
+		{name: "other algorithm than configured", cfg: hs256, token: sign(t, jwt.SigningMethodHS512, []byte(secret), valid(nil)), wantErr: true},
+		{name: "HMAC signed with the RSA public key", cfg: rs256, token: sign(t, jwt.SigningMethodHS256, publicPEM, valid(nil)), wantErr: true},
+		{name: "RS256", cfg: rs256, token: sign(t, jwt.SigningMethodRS256, privateKey, valid(nil)), want: RoleCurator},
+		{name: "expired", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"exp": now.Add(-time.Second * 30).Unix()})), wantErr: true},
+		{name: "expired within the leeway", cfg: withLeeway, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"exp": now.Add(-time.Second * 30).Unix()})), want: RoleCurator},
+		{name: "without expiry", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"exp": nil})), wantErr: true},
+		{name: "not valid yet", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"nbf": now.Add(time.Hour).Unix()})), wantErr: true},
+		{name: "valid from now", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"nbf": now.Add(-time.Second).Unix()})), want: RoleCurator},
+		{name: "wrong issuer", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"iss": "elsewhere"})), wantErr: true},
+		{name: "without issuer", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"iss": nil})), wantErr: true},
+		{name: "wrong audience", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"aud": "other"})), wantErr: true},
+		{name: "without audience", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"aud": nil})), wantErr: true},
+		{name: "without known role", cfg: hs256, token: sign(t, jwt.SigningMethodHS256, []byte(secret), valid(jwt.MapClaims{"role": "owner"})), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := newJWTVerifier(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			subject, role, err := verifier.verify(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("verify succeeded with role %s, want an error", role)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if role != tt.want || subject != "jane" {
+				t.Errorf("verify = %q, %s, want jane, %s", subject, role, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewJWTVerifierConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.JWTConfig
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "no key disables tokens", cfg: config.JWTConfig{Algorithm: "HS256"}, wantNil: true},
+		{name: "lowercase algorithm", cfg: config.JWTConfig{Algorithm: "hs256", Secret: "s"}},
+		{name: "unsupported algorithm", cfg: config.JWTConfig{Algorithm: "ES256", Secret: "s"}, wantErr: true},
+		{name: "HS256 without secret", cfg: config.JWTConfig{Algorithm: "HS256", PublicKeyFile: "key.pem"}, wantErr: true},
+		{name: "RS256 without key file", cfg: config.JWTConfig{Algorithm: "RS256", Secret: "s"}, wantErr: true},
+		{name: "missing key file", cfg: config.JWTConfig{Algorithm: "RS256", PublicKeyFile: filepath.Join(t.TempDir(), "missing.pem")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := newJWTVerifier(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && (verifier == nil) != tt.wantNil {
+				t.Errorf("verifier = %v, want nil %t", verifier, tt.wantNil)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shaik80/ODIW/config"
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+// HeaderAPIKey is the request header carrying an API key
+const HeaderAPIKey = "X-API-Key"
+
+// localsKey stores the authenticated Principal in the fiber context
+const localsKey = "auth.principal"
+
+// Principal is the caller a request was authenticated as
+type Principal struct {
+	// Name is the API key name or the JWT subject
+	Name string
+	Role Role
+}
+
+type apiKey struct {
+	name string
+	hash [sha256.Size]byte
+	role Role
+}
+
+// Authenticator checks API keys and JWTs and enforces the role of a route
+type Authenticator struct {
+	enabled bool
+	keys    []apiKey
+	jwt     *jwtVerifier
+}
+
+// New builds an Authenticator from the auth configuration
+func New(cfg config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{enabled: cfg.Enabled}
+	if !cfg.Enabled {
+		return a, nil
+	}
+
+	for i, key := range cfg.APIKeys {
+		if key.Key == "" {
+			return nil, fmt.Errorf("auth.api_keys[%d] has an empty key", i)
+		}
+		role, ok := ParseRole(key.Role)
+		if !ok {
+			return nil, fmt.Errorf("auth.api_keys[%d] has unknown role %q", i, key.Role)
+		}
+		name := key.Name
+		if name == "" {
+			name = fmt.Sprintf("api-key-%d", i)
+		}
+		a.keys = append(a.keys, apiKey{name: name, hash: sha256.Sum256([]byte(key.Key)), role: role})
+	}
+
+	verifier, err := newJWTVerifier(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
+	a.jwt = verifier
+	return a, nil
+}
+
+// Require returns a middleware rejecting requests whose credentials do not grant role
+func (a *Authenticator) Require(role Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, err := a.authenticate(c)
+		if err != nil {
+			return err
+		}
+		if !principal.Role.Allows(role) {
+			return errs.Forbidden("%s role is required", role)
+		}
+		c.Locals(localsKey, principal)
+		return c.Next()
+	}
+}
+
+// authenticate resolves the credentials of the request
+func (a *Authenticator) authenticate(c *fiber.Ctx) (Principal, error) {
+	if !a.enabled {
+		return Principal{Name: "anonymous", Role: RoleAdmin}, nil
+	}
+
+	if key := c.Get(HeaderAPIKey); key != "" {
+		return a.checkAPIKey(key)
+	}
+
+	scheme, credentials, _ := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	credentials = strings.TrimSpace(credentials)
+	switch {
+	case credentials == "":
+		return Principal{}, errs.Unauthenticated("credentials are required")
+	case strings.EqualFold(scheme, "ApiKey"):
+		return a.checkAPIKey(credentials)
+	case strings.EqualFold(scheme, "Bearer"):
+		if a.jwt == nil {
+			return Principal{}, errs.Unauthenticated("bearer tokens are not accepted")
+		}
+		subject, role, err := a.jwt.verify(credentials)
+		if err != nil {
+			return Principal{}, errs.Unauthenticated("%v", err)
+		}
+		return Principal{Name: subject, Role: role}, nil
+	default:
+		return Principal{}, errs.Unauthenticated("unsupported authorization scheme %q", scheme)
+	}
+}
+
+// checkAPIKey compares hashes in constant time so the key length does not leak
+func (a *Authenticator) checkAPIKey(key string) (Principal, error) {
+	hash := sha256.Sum256([]byte(key))
+	for _, candidate := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], candidate.hash[:]) == 1 {
+			return Principal{Name: candidate.name, Role: candidate.role}, nil
+		}
+	}
+	return Principal{}, errs.Unauthenticated("invalid API key")
+}
+
+// PrincipalFrom returns the caller stored by Require, if any
+func PrincipalFrom(c *fiber.Ctx) (Principal, bool) {
+	principal, ok := c.Locals(localsKey).(Principal)
+	return principal, ok
+}
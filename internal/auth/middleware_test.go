@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shaik80/ODIW/config"
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+// newTestAuthApp serves GET /curator behind Require(RoleCurator), answering with the
+// authenticated principal, and maps the auth errors to their status codes
+func newTestAuthApp(t *testing.T, cfg config.AuthConfig) *fiber.App {
+	t.Helper()
+	authn, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := fiber.New(fiber.Config{ErrorHandler: func(c *fiber.Ctx, err error) error {
+		switch {
+		case errors.Is(err, errs.ErrUnauthenticated):
+			return c.SendStatus(fiber.StatusUnauthorized)
+		case errors.Is(err, errs.ErrForbidden):
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}})
+	app.Get("/curator", authn.Require(RoleCurator), func(c *fiber.Ctx) error {
+		principal, ok := PrincipalFrom(c)
+		if !ok {
+			return errors.New("no principal stored")
+		}
+		return c.SendString(principal.Name + ":" + string(principal.Role))
+	})
+	return app
+}
+
+func TestRequire(t *testing.T) {
+	const secret = "test-secret"
+	cfg := config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "dashboard", Key: "viewer-key", Role: "viewer"},
+			{Name: "curation-team", Key: "curator-key", Role: "curator"},
+			{Name: "ops", Key: "admin-key", Role: "admin"},
+		},
+		JWT: config.JWTConfig{Algorithm: "HS256", Secret: secret},
+	}
+	token := func(role string) string {
+		return "Bearer " + sign(t, jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{"sub": "jane", "role": role, "exp": time.Now().Add(time.Hour).Unix()})
+	}
+
+	tests := []struct {
+		name       string
+		cfg        config.AuthConfig
+		header     string
+		value      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "missing credentials", cfg: cfg, wantStatus: fiber.StatusUnauthorized},
+		{name: "wrong key", cfg: cfg, header: HeaderAPIKey, value: "guess", wantStatus: fiber.StatusUnauthorized},
+		{name: "wrong key in authorization", cfg: cfg, header: fiber.HeaderAuthorization, value: "ApiKey guess", wantStatus: fiber.StatusUnauthorized},
+		{name: "unsupported scheme", cfg: cfg, header: fiber.HeaderAuthorization, value: "Basic Y3VyYXRvcjprZXk=", wantStatus: fiber.StatusUnauthorized},
+		{name: "invalid token", cfg: cfg, header: fiber.HeaderAuthorization, value: "Bearer not.a-token", wantStatus: fiber.StatusUnauthorized},
+		{name: "viewer key", cfg: cfg, header: HeaderAPIKey, value: "viewer-key", wantStatus: fiber.StatusForbidden},
+		{name: "viewer token", cfg: cfg, header: fiber.HeaderAuthorization, value: token("viewer"), wantStatus: fiber.StatusForbidden},
+		{name: "curator key", cfg: cfg, header: HeaderAPIKey, value: "curator-key", wantStatus: fiber.StatusOK, wantBody: "curation-team:curator"},
+		{name: "curator key in authorization", cfg: cfg, header: fiber.HeaderAuthorization, value: "ApiKey curator-key", wantStatus: fiber.StatusOK, wantBody: "curation-team:curator"},
+		{name: "admin key", cfg: cfg, header: HeaderAPIKey, value: "admin-key", wantStatus: fiber.StatusOK, wantBody: "ops:admin"},
+		{name: "curator token", cfg: cfg, header: fiber.HeaderAuthorization, value: token("curator"), wantStatus: fiber.StatusOK, wantBody: "jane:curator"},
+		{name: "admin token", cfg: cfg, header: fiber.HeaderAuthorization, value: token("admin"), wantStatus: fiber.StatusOK, wantBody: "jane:admin"},
+		{name: "token without verifier", cfg: config.AuthConfig{Enabled: true, APIKeys: cfg.APIKeys}, header: fiber.HeaderAuthorization, value: token("admin"), wantStatus: fiber.StatusUnauthorized},
+		{name: "disabled", cfg: config.AuthConfig{}, wantStatus: fiber.StatusOK, wantBody: "anonymous:admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestAuthApp(t, tt.cfg)
+			req := httptest.NewRequest(http.MethodGet, "/curator", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantBody != "" {
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := string(body); got != tt.wantBody {
+					t.Errorf("principal = %q, want %q", got, tt.wantBody)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidKeys(t *testing.T) {
+	for _, keys := range [][]config.APIKeyConfig{
+		{{Name: "empty", Role: "admin"}},
+		{{Name: "unknown role", Key: "k", Role: "owner"}},
+	} {
+		if _, err := New(config.AuthConfig{Enabled: true, APIKeys: keys}); err == nil {
+			t.Errorf("New accepted %+v", keys)
+		}
+	}
+}
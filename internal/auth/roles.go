@@ -0,0 +1,42 @@
+package auth
+
+import "strings"
+
+// Role grants access to a set of routes. Roles are ordered: every role includes the
+// permissions of the roles below it.
+type Role string
+
+const (
+	RoleViewer  Role = "viewer"
+	RoleCurator Role = "curator"
+	RoleAdmin   Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer:  1,
+	RoleCurator: 2,
+	RoleAdmin:   3,
+}
+
+// ParseRole returns the role with the given name, or false when the name is unknown
+func ParseRole(name string) (Role, bool) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	_, ok := roleRanks[role]
+	return role, ok
+}
+
+// Allows reports whether the role includes the required role
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required] && roleRanks[r] > 0
+}
+
+// highest returns the most privileged known role of the names
+func highest(names []string) (Role, bool) {
+	var best Role
+	for _, name := range names {
+		if role, ok := ParseRole(name); ok && roleRanks[role] > roleRanks[best] {
+			best = role
+		}
+	}
+	return best, best != ""
+}
@@ -3,8 +3,15 @@ package connect
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"math/rand"
+	"net"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/shaik80/ODIW/config"
 
@@ -16,33 +23,157 @@ import (
 
 var Client *opensearchapi.Client
 
-// InitOpenSearchClient initializes the global OpenSearch client
+const (
+	maxRetryBackoff = 10 * time.Second
+	maxWaitDelay    = 30 * time.Second
+)
+
+// InitOpenSearchClient creates the global OpenSearch client and waits up to
+// opensearch.startup_timeout for the cluster to become available
 func InitOpenSearchClient(cfg config.Config) error {
+	client, err := NewClient(cfg.OpenSearch)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if timeout := cfg.OpenSearch.StartupTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := WaitForCluster(ctx, client); err != nil {
+		return err
+	}
+	Client = client
+	return nil
+}
+
+// NewClient builds a client from the connection, TLS and retry settings in cfg. It
+// does not contact the cluster.
+func NewClient(cfg config.OpenSearch) (*opensearchapi.Client, error) {
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Only connecting is bounded here. Whole calls are bounded by the deadline of their
+	// context, see config.OperationTimeouts, so that long bulk writes are not cut short.
+	dialer := &net.Dialer{Timeout: cfg.RequestTimeout, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: cfg.RequestTimeout,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
 	opensearchCfg := opensearch.Config{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		}, Addresses: []string{
-			fmt.Sprintf("https://%s:%s", cfg.OpenSearch.Host, cfg.OpenSearch.Port),
-		},
-		Username: cfg.OpenSearch.Username,
-		Password: cfg.OpenSearch.Password,
+		Transport:     transport,
+		Addresses:     Addresses(cfg),
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		RetryOnStatus: cfg.RetryOnStatus,
+		MaxRetries:    cfg.MaxRetries,
+		// Timed out attempts are not retried: a write may have been applied already
+		DisableRetry: cfg.MaxRetries == 0,
+		RetryBackoff: retryBackoff(cfg.RetryBackoff),
 	}
 	client, err := opensearchapi.NewClient(opensearchapi.Config{Client: opensearchCfg})
 	if err != nil {
-		return fmt.Errorf("error creating OpenSearch client: %w", err)
+		return nil, fmt.Errorf("error creating OpenSearch client: %w", err)
 	}
-	lp.Logs.Infof("openseach connected successfully")
-	Client = client
+	return client, nil
+}
 
-	ctx := context.Background()
-	// Print OpenSearch version information on console.
-	infoResp, err := client.Info(ctx, nil)
+// Addresses returns the configured node URLs, or the single URL made of scheme, host
+// and port when none are listed
+func Addresses(cfg config.OpenSearch) []string {
+	if len(cfg.Addresses) > 0 {
+		return cfg.Addresses
+	}
+	scheme := strings.ToLower(cfg.Scheme)
+	if scheme == "" {
+		scheme = "https"
+	}
+	return []string{fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(cfg.Host, cfg.Port))}
+}
+
+// newTLSConfig loads the CA bundle and client certificate named in cfg
+func newTLSConfig(cfg config.OpenSearch) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading opensearch.ca_cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("opensearch.ca_cert %s contains no PEM certificates", cfg.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading opensearch client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// retryBackoff doubles base on every attempt, capped at maxRetryBackoff, and adds up
+// to 20% jitter so retries from several instances do not line up
+func retryBackoff(base time.Duration) func(int) time.Duration {
+	if base <= 0 {
+		return nil
+	}
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+			delay *= 2
+		}
+		delay = min(delay, maxRetryBackoff)
+		return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+	}
+}
+
+// WaitForCluster polls the cluster health with backoff until the cluster answers with
+// a yellow or green status, or ctx is done
+func WaitForCluster(ctx context.Context, client *opensearchapi.Client) error {
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		err := clusterAvailable(ctx, client)
+		if err == nil {
+			return nil
+		}
+		lp.FromContext(ctx).Warn("OpenSearch is not available yet", "attempt", attempt, "error", err, "retry_in", delay.String())
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("OpenSearch did not become available: %w", errors.Join(ctx.Err(), err))
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxWaitDelay)
+	}
+}
+
+// clusterAvailable checks the cluster health once and logs the cluster details
+func clusterAvailable(ctx context.Context, client *opensearchapi.Client) error {
+	health, err := client.Cluster.Health(ctx, nil)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Cluster INFO:\n  Cluster Name: %s\n  Cluster UUID: %s\n  Version Number: %s\n", infoResp.ClusterName, infoResp.ClusterUUID, infoResp.Version.Number)
-
+	if health.Status == "red" {
+		return errors.New("cluster status is red")
+	}
+	info, err := client.Info(ctx, nil)
+	if err != nil {
+		return err
+	}
+	lp.FromContext(ctx).Info("connected to OpenSearch", "cluster_name", info.ClusterName, "cluster_uuid", info.ClusterUUID, "version", info.Version.Number, "status", health.Status)
 	return nil
 }
@@ -0,0 +1,191 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// maxBanners bounds the number of banners listed in one request
+const maxBanners = 1000
+
+// GetBanners lists every banner ordered by position, then ID
+func GetBanners(ctx context.Context) ([]*models.Banner, error) {
+	searchRequest := map[string]interface{}{
+		"size": maxBanners,
+		"query": map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"position": map[string]interface{}{"order": "asc", "unmapped_type": "integer"}},
+			map[string]interface{}{"id": map[string]interface{}{"order": "asc", "unmapped_type": "keyword"}},
+		},
+	}
+
+	return searchBanners(ctx, searchRequest)
+}
+
+// GetActiveBanners lists the enabled banners published at now for locale, ordered by
+// position, then ID. It matches models.Banner.ActiveAt: banners without an enabled flag,
+// a bound or target locales are not restricted by it, and a locale such as ar-SA also
+// matches banners targeting ar. A malformed bound is left out of the index by
+// ignore_malformed and listed in _ignored instead; such banners are never active.
+func GetActiveBanners(ctx context.Context, now time.Time, locale string) ([]*models.Banner, error) {
+	locale = strings.ToLower(locale)
+	language, _, _ := strings.Cut(locale, "-")
+	millis := now.UnixMilli()
+
+	// unbounded matches the banners without field or with one satisfying condition
+	unbounded := func(field string, condition map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []interface{}{
+					map[string]interface{}{"bool": map[string]interface{}{
+						"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": field}},
+					}},
+					condition,
+				},
+				"minimum_should_match": 1,
+			},
+		}
+	}
+	searchRequest := map[string]interface{}{
+		"size": maxBanners,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must_not": []interface{}{
+					map[string]interface{}{"term": map[string]interface{}{"enabled": false}},
+					map[string]interface{}{"terms": map[string]interface{}{"_ignored": []string{"startsAt", "endsAt"}}},
+				},
+				"filter": []interface{}{
+					unbounded("startsAt", map[string]interface{}{
+						"range": map[string]interface{}{"startsAt": map[string]interface{}{"lte": millis, "format": "epoch_millis"}},
+					}),
+					unbounded("endsAt", map[string]interface{}{
+						"range": map[string]interface{}{"endsAt": map[string]interface{}{"gt": millis, "format": "epoch_millis"}},
+					}),
+					unbounded("locales", map[string]interface{}{
+						"terms": map[string]interface{}{"locales": []string{locale, language}},
+					}),
+				},
+			},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"position": map[string]interface{}{"order": "asc", "unmapped_type": "integer"}},
+			map[string]interface{}{"id": map[string]interface{}{"order": "asc", "unmapped_type": "keyword"}},
+		},
+	}
+	return searchBanners(ctx, searchRequest)
+}
+
+// searchBanners runs a search on the banners index and decodes the hits
+func searchBanners(ctx context.Context, searchRequest map[string]interface{}) ([]*models.Banner, error) {
+	res, err := search(ctx, "banners", searchRequest)
+	if err != nil {
+		// No banner has been stored yet
+		if isNotFound(res.Inspect().Response) {
+			return []*models.Banner{}, nil
+		}
+		return nil, storageError(res.Inspect().Response, err, "error listing banners")
+	}
+
+	banners := make([]*models.Banner, len(res.Hits.Hits))
+	for i, hit := range res.Hits.Hits {
+		var banner models.Banner
+		if err := json.Unmarshal(hit.Source, &banner); err != nil {
+			return nil, err
+		}
+		banners[i] = &banner
+	}
+	return banners, nil
+}
+
+// GetBannerByID retrieves a single banner from the banners index
+func GetBannerByID(ctx context.Context, id string) (*models.Banner, error) {
+	getResponse, err := connect.Client.Document.Get(ctx, opensearchapi.DocumentGetReq{
+		Index:      "banners",
+		DocumentID: id,
+	})
+	if isNotFound(getResponse.Inspect().Response) {
+		return nil, errs.NotFound("banner", id)
+	}
+	if err != nil {
+		return nil, storageError(getResponse.Inspect().Response, err, "error getting banner %s", id)
+	}
+
+	var banner models.Banner
+	if err := json.Unmarshal(getResponse.Source, &banner); err != nil {
+		return nil, fmt.Errorf("error decoding banner response: %s", err)
+	}
+	return &banner, nil
+}
+
+// CreateBanner indexes a new banner, failing with a conflict when the ID is taken
+func CreateBanner(ctx context.Context, banner *models.Banner) error {
+	return indexBanner(ctx, banner, "create")
+}
+
+// UpdateBanner replaces an existing banner
+func UpdateBanner(ctx context.Context, banner *models.Banner) error {
+	existsResp, err := connect.Client.Document.Exists(ctx, opensearchapi.DocumentExistsReq{
+		Index:      "banners",
+		DocumentID: banner.ID,
+	})
+	if existsResp != nil && existsResp.StatusCode == http.StatusNotFound {
+		return errs.NotFound("banner", banner.ID)
+	}
+	if err != nil {
+		return storageError(existsResp, err, "error checking banner %s", banner.ID)
+	}
+	return indexBanner(ctx, banner, "index")
+}
+
+func indexBanner(ctx context.Context, banner *models.Banner, opType string) error {
+	data, err := json.Marshal(banner)
+	if err != nil {
+		return err
+	}
+
+	insertResp, err := connect.Client.Index(ctx, opensearchapi.IndexReq{
+		Index:      "banners",
+		DocumentID: banner.ID,
+		Body:       strings.NewReader(string(data)),
+		Params: opensearchapi.IndexParams{
+			OpType:  opType,
+			Refresh: "true",
+		},
+	})
+	if resp := insertResp.Inspect().Response; resp != nil && resp.StatusCode == http.StatusConflict {
+		return errs.Conflict("banner %s already exists", banner.ID)
+	}
+	if err != nil {
+		return storageError(insertResp.Inspect().Response, err, "error indexing banner %s", banner.ID)
+	}
+	lp.FromContext(ctx).Debug("indexed banner", "index", insertResp.Index, "banner_id", insertResp.ID)
+	return nil
+}
+
+// DeleteBanner removes a banner document
+func DeleteBanner(ctx context.Context, id string) error {
+	deleteResp, err := connect.Client.Document.Delete(ctx, opensearchapi.DocumentDeleteReq{
+		Index:      "banners",
+		DocumentID: id,
+		Params:     opensearchapi.DocumentDeleteParams{Refresh: "true"},
+	})
+	if isNotFound(deleteResp.Inspect().Response) {
+		return errs.NotFound("banner", id)
+	}
+	if err != nil {
+		return storageError(deleteResp.Inspect().Response, err, "error deleting banner %s", id)
+	}
+	return nil
+}
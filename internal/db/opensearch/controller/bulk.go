@@ -0,0 +1,63 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// BulkIndexVideos indexes the videos with a single bulk request. A failed document does not
+// fail the request; its error is reported in the result at the same position.
+func BulkIndexVideos(ctx context.Context, videos []*models.Video) ([]models.BulkItemResult, error) {
+	if len(videos) == 0 {
+		return []models.BulkItemResult{}, nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, video := range videos {
+		action := map[string]interface{}{
+			"index": map[string]interface{}{"_index": "videos", "_id": video.VideoID},
+		}
+		if err := encoder.Encode(action); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(video); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := connect.Client.Bulk(ctx, opensearchapi.BulkReq{
+		Body: &body,
+		Params: opensearchapi.BulkParams{
+			Refresh: "true",
+		},
+	})
+	if err != nil {
+		return nil, storageError(res.Inspect().Response, err, "error bulk indexing %d videos", len(videos))
+	}
+
+	results := make([]models.BulkItemResult, len(videos))
+	for i, video := range videos {
+		results[i].ID = video.VideoID
+		if i >= len(res.Items) {
+			results[i].Error = "missing from bulk response"
+			continue
+		}
+		item := res.Items[i]["index"]
+		if item.Error != nil {
+			results[i].Error = fmt.Sprintf("%s: %s", item.Error.Type, item.Error.Reason)
+			continue
+		}
+		results[i].Result = item.Result
+	}
+	lp.FromContext(ctx).Debug("bulk indexed videos", "count", len(videos), "errors", res.Errors)
+
+	return results, nil
+}
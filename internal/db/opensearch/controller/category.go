@@ -0,0 +1,222 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// maxCategories bounds the number of categories listed in one request
+const maxCategories = 10000
+
+// GetCategories lists every category ordered by sort order, then slug
+func GetCategories(ctx context.Context) ([]*models.Category, error) {
+	searchRequest := map[string]interface{}{
+		"size": maxCategories,
+		"query": map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"sortOrder": map[string]interface{}{"order": "asc", "unmapped_type": "integer"}},
+			map[string]interface{}{"slug": map[string]interface{}{"order": "asc", "unmapped_type": "keyword"}},
+		},
+	}
+
+	res, err := search(ctx, "categories", searchRequest)
+	if err != nil {
+		// No category has been stored yet
+		if isNotFound(res.Inspect().Response) {
+			return []*models.Category{}, nil
+		}
+		return nil, storageError(res.Inspect().Response, err, "error listing categories")
+	}
+
+	categories := make([]*models.Category, len(res.Hits.Hits))
+	for i, hit := range res.Hits.Hits {
+		var category models.Category
+		if err := json.Unmarshal(hit.Source, &category); err != nil {
+			return nil, err
+		}
+		categories[i] = &category
+	}
+	return categories, nil
+}
+
+// GetCategoryBySlug retrieves a single category from the categories index
+func GetCategoryBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	getResponse, err := connect.Client.Document.Get(ctx, opensearchapi.DocumentGetReq{
+		Index:      "categories",
+		DocumentID: slug,
+	})
+	if isNotFound(getResponse.Inspect().Response) {
+		return nil, errs.NotFound("category", slug)
+	}
+	if err != nil {
+		return nil, storageError(getResponse.Inspect().Response, err, "error getting category %s", slug)
+	}
+
+	var category models.Category
+	if err := json.Unmarshal(getResponse.Source, &category); err != nil {
+		return nil, fmt.Errorf("error decoding category response: %s", err)
+	}
+	return &category, nil
+}
+
+// CreateCategory indexes a new category, failing with a conflict when the slug is taken
+func CreateCategory(ctx context.Context, category *models.Category) error {
+	return indexCategory(ctx, category, "create")
+}
+
+// UpdateCategory replaces an existing category
+func UpdateCategory(ctx context.Context, category *models.Category) error {
+	existsResp, err := connect.Client.Document.Exists(ctx, opensearchapi.DocumentExistsReq{
+		Index:      "categories",
+		DocumentID: category.Slug,
+	})
+	if existsResp != nil && existsResp.StatusCode == http.StatusNotFound {
+		return errs.NotFound("category", category.Slug)
+	}
+	if err != nil {
+		return storageError(existsResp, err, "error checking category %s", category.Slug)
+	}
+	return indexCategory(ctx, category, "index")
+}
+
+func indexCategory(ctx context.Context, category *models.Category, opType string) error {
+	data, err := json.Marshal(category)
+	if err != nil {
+		return err
+	}
+
+	insertResp, err := connect.Client.Index(ctx, opensearchapi.IndexReq{
+		Index:      "categories",
+		DocumentID: category.Slug,
+		Body:       strings.NewReader(string(data)),
+		Params: opensearchapi.IndexParams{
+			OpType:  opType,
+			Refresh: "true",
+		},
+	})
+	if resp := insertResp.Inspect().Response; resp != nil && resp.StatusCode == http.StatusConflict {
+		return errs.Conflict("category %s already exists", category.Slug)
+	}
+	if err != nil {
+		return storageError(insertResp.Inspect().Response, err, "error indexing category %s", category.Slug)
+	}
+	lp.FromContext(ctx).Debug("indexed category", "index", insertResp.Index, "slug", insertResp.ID)
+	return nil
+}
+
+// DeleteCategory removes a category document
+func DeleteCategory(ctx context.Context, slug string) error {
+	deleteResp, err := connect.Client.Document.Delete(ctx, opensearchapi.DocumentDeleteReq{
+		Index:      "categories",
+		DocumentID: slug,
+		Params:     opensearchapi.DocumentDeleteParams{Refresh: "true"},
+	})
+	if isNotFound(deleteResp.Inspect().Response) {
+		return errs.NotFound("category", slug)
+	}
+	if err != nil {
+		return storageError(deleteResp.Inspect().Response, err, "error deleting category %s", slug)
+	}
+	return nil
+}
+
+// CountVideosByCategory counts the videos in each of the given categories
+func CountVideosByCategory(ctx context.Context, slugs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(slugs))
+	if len(slugs) == 0 {
+		return counts, nil
+	}
+
+	searchRequest := map[string]interface{}{
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"categories": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field":   "categories.keyword",
+					"include": slugs,
+					"size":    len(slugs),
+				},
+			},
+		},
+	}
+
+	res, err := search(ctx, "videos", searchRequest)
+	if err != nil {
+		return nil, storageError(res.Inspect().Response, err, "error counting videos per category")
+	}
+
+	var aggs struct {
+		Categories struct {
+			Buckets []struct {
+				Key      string `json:"key"`
+				DocCount int    `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"categories"`
+	}
+	if len(res.Aggregations) > 0 {
+		if err := json.Unmarshal(res.Aggregations, &aggs); err != nil {
+			return nil, err
+		}
+	}
+	for _, bucket := range aggs.Categories.Buckets {
+		counts[bucket.Key] = bucket.DocCount
+	}
+	return counts, nil
+}
+
+// CountVideosInSubtrees counts for each key the distinct videos in any of its category slugs
+// with one filter bucket per key
+func CountVideosInSubtrees(ctx context.Context, subtrees map[string][]string) (map[string]int, error) {
+	counts := make(map[string]int, len(subtrees))
+	if len(subtrees) == 0 {
+		return counts, nil
+	}
+
+	filters := make(map[string]interface{}, len(subtrees))
+	for key, slugs := range subtrees {
+		filters[key] = map[string]interface{}{
+			"terms": map[string]interface{}{"categories.keyword": slugs},
+		}
+	}
+	searchRequest := map[string]interface{}{
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"subtrees": map[string]interface{}{
+				"filters": map[string]interface{}{"filters": filters},
+			},
+		},
+	}
+
+	res, err := search(ctx, "videos", searchRequest)
+	if err != nil {
+		return nil, storageError(res.Inspect().Response, err, "error counting videos per category subtree")
+	}
+
+	var aggs struct {
+		Subtrees struct {
+			Buckets map[string]struct {
+				DocCount int `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"subtrees"`
+	}
+	if len(res.Aggregations) > 0 {
+		if err := json.Unmarshal(res.Aggregations, &aggs); err != nil {
+			return nil, err
+		}
+	}
+	for key, bucket := range aggs.Subtrees.Buckets {
+		counts[key] = bucket.DocCount
+	}
+	return counts, nil
+}
@@ -0,0 +1,205 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// updateCategoriesScript removes params.remove from the categories of a video and then
+// adds the missing ones of params.add, leaving videos that already match untouched
+const updateCategoriesScript = `
+if (ctx._source.categories == null) { ctx._source.categories = new ArrayList(); }
+def remove = params.remove;
+boolean changed = ctx._source.categories.removeIf(c -> remove.contains(c));
+for (String c : params.add) {
+  if (!ctx._source.categories.contains(c)) { ctx._source.categories.add(c); changed = true; }
+}
+if (!changed) { ctx.op = 'noop'; }
+`
+
+// UpdateVideoCategories starts an update by query task that applies the category update to
+// the matching videos and returns without waiting for it. Videos changed concurrently are
+// skipped and counted as version conflicts.
+func UpdateVideoCategories(ctx context.Context, update models.CategoryUpdate) (*models.CategoryTask, error) {
+	filters, err := baseFilters(update.Search.Filters)
+	if err != nil {
+		return nil, err
+	}
+	filters = append(filters, categoryFilters(update.Search.Filters)...)
+	filters = append(filters, creatorFilters(update.Search.Filters)...)
+
+	add, remove := update.Add, update.Remove
+	if add == nil {
+		add = []string{}
+	}
+	if remove == nil {
+		remove = []string{}
+	}
+	body := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":   textQuery(update.Search.Query, update.Search.Match),
+				"filter": filters,
+			},
+		},
+		"script": map[string]interface{}{
+			"lang":   "painless",
+			"source": strings.TrimSpace(updateCategoriesScript),
+			"params": map[string]interface{}{"add": add, "remove": remove},
+		},
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := connect.Client.UpdateByQuery(ctx, opensearchapi.UpdateByQueryReq{
+		Indices: []string{"videos"},
+		Body:    bytes.NewReader(data),
+		Params: opensearchapi.UpdateByQueryParams{
+			Conflicts:         "proceed",
+			Refresh:           opensearchapi.ToPointer(true),
+			WaitForCompletion: opensearchapi.ToPointer(false),
+		},
+	})
+	if err != nil {
+		return nil, storageError(res.Inspect().Response, err, "error starting category update")
+	}
+	lp.FromContext(ctx).Info("started category update", "task_id", res.Task, "add", add, "remove", remove)
+	return &models.CategoryTask{ID: res.Task}, nil
+}
+
+// taskCounts are the counters reported by an update by query task
+type taskCounts struct {
+	Total            int `json:"total"`
+	Updated          int `json:"updated"`
+	Noops            int `json:"noops"`
+	VersionConflicts int `json:"version_conflicts"`
+}
+
+// GetCategoryTask reports the progress of a task started by UpdateVideoCategories. The
+// typed tasks response drops the status, so the raw body is decoded.
+func GetCategoryTask(ctx context.Context, taskID string) (*models.CategoryTask, error) {
+	res, err := connect.Client.Tasks.Get(ctx, opensearchapi.TasksGetReq{TaskID: taskID})
+	if isNotFound(res.Inspect().Response) {
+		return nil, errs.NotFound("task", taskID)
+	}
+	if err != nil {
+		return nil, storageError(res.Inspect().Response, err, "error getting task %s", taskID)
+	}
+
+	var raw struct {
+		Completed bool `json:"completed"`
+		Task      struct {
+			Status taskCounts `json:"status"`
+		} `json:"task"`
+		Response *struct {
+			taskCounts
+			Failures []json.RawMessage `json:"failures"`
+		} `json:"response"`
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	counts := raw.Task.Status
+	task := &models.CategoryTask{ID: taskID, Completed: raw.Completed}
+	if raw.Response != nil {
+		counts = raw.Response.taskCounts
+		for _, failure := range raw.Response.Failures {
+			task.Failures = append(task.Failures, string(failure))
+		}
+	}
+	if len(raw.Error) > 0 {
+		task.Error = string(raw.Error)
+	}
+	task.Total = counts.Total
+	task.Updated = counts.Updated
+	task.Noops = counts.Noops
+	task.VersionConflicts = counts.VersionConflicts
+	return task, nil
+}
+
+// changeCategoriesScript applies a models.CategoryChange to one video, leaving the video
+// untouched when its categories stay the same
+const changeCategoriesScript = `
+List current = ctx._source.categories == null ? new ArrayList() : ctx._source.categories;
+List updated = new ArrayList();
+if (params.replace != null) {
+  for (String c : params.replace) { if (!updated.contains(c)) { updated.add(c); } }
+} else {
+  for (def c : current) { if (!params.remove.contains(c)) { updated.add(c); } }
+  for (String c : params.add) { if (!updated.contains(c)) { updated.add(c); } }
+}
+if (updated.equals(current)) { ctx.op = 'none'; } else { ctx._source.categories = updated; }
+`
+
+// ChangeVideoCategories edits the categories of one video with a scripted partial update,
+// so the rest of the document is not rewritten from a possibly stale copy, and returns the
+// resulting categories
+func ChangeVideoCategories(ctx context.Context, videoID string, change models.CategoryChange) ([]string, error) {
+	add, remove := change.Add, change.Remove
+	if add == nil {
+		add = []string{}
+	}
+	if remove == nil {
+		remove = []string{}
+	}
+	body := map[string]interface{}{
+		"script": map[string]interface{}{
+			"lang":   "painless",
+			"source": strings.TrimSpace(changeCategoriesScript),
+			"params": map[string]interface{}{"add": add, "remove": remove, "replace": change.Replace},
+		},
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := connect.Client.Update(ctx, opensearchapi.UpdateReq{
+		Index:      "videos",
+		DocumentID: videoID,
+		Body:       bytes.NewReader(data),
+		Params: opensearchapi.UpdateParams{
+			Refresh:         "true",
+			RetryOnConflict: opensearchapi.ToPointer(3),
+			Source:          "categories",
+		},
+	})
+	if isNotFound(res.Inspect().Response) {
+		return nil, errs.NotFound("video", videoID)
+	}
+	if err != nil {
+		return nil, storageError(res.Inspect().Response, err, "error updating categories of video %s", videoID)
+	}
+
+	// The typed update response drops the updated source
+	var raw struct {
+		Result string `json:"result"`
+		Get    struct {
+			Source struct {
+				Categories []string `json:"categories"`
+			} `json:"_source"`
+		} `json:"get"`
+	}
+	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+	lp.FromContext(ctx).Debug("changed video categories", "video_id", videoID, "result", raw.Result)
+
+	categories := raw.Get.Source.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	return categories, nil
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// InsertOrUpdateCreator indexes the creator document, replacing any existing one with the same ID
+func InsertOrUpdateCreator(ctx context.Context, creator *models.Creator) error {
+	data, err := json.Marshal(creator)
+	if err != nil {
+		return err
+	}
+
+	req := opensearchapi.IndexReq{
+		Index:      "creators",
+		DocumentID: creator.CreatorID,
+		Body:       strings.NewReader(string(data)),
+		Params: opensearchapi.IndexParams{
+			Refresh: "true",
+		},
+	}
+
+	insertResp, err := connect.Client.Index(ctx, req)
+	if err != nil {
+		return storageError(insertResp.Inspect().Response, err, "error indexing creator %s", creator.CreatorID)
+	}
+	lp.FromContext(ctx).Debug("indexed creator", "index", insertResp.Index, "creator_id", insertResp.ID)
+
+	return nil
+}
+
+// GetCreatorByID retrieves a single creator from the creators index
+func GetCreatorByID(ctx context.Context, creatorID string) (*models.Creator, error) {
+	req := opensearchapi.DocumentGetReq{
+		Index:      "creators",
+		DocumentID: creatorID,
+	}
+
+	getResponse, err := connect.Client.Document.Get(ctx, req)
+	if isNotFound(getResponse.Inspect().Response) {
+		return nil, errs.NotFound("creator", creatorID)
+	}
+	if err != nil {
+		return nil, storageError(getResponse.Inspect().Response, err, "error getting creator with ID %s", creatorID)
+	}
+
+	var creator models.Creator
+	if err := json.Unmarshal(getResponse.Source, &creator); err != nil {
+		return nil, fmt.Errorf("error decoding creator response: %s", err)
+	}
+
+	return &creator, nil
+}
+
+// GetCreators lists creators ordered by name with pagination
+func GetCreators(ctx context.Context, from int, size int) (int, []*models.Creator, error) {
+	searchRequest := map[string]interface{}{
+		"from": from,
+		"size": size,
+		"query": map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"name.keyword": map[string]interface{}{"order": "asc", "unmapped_type": "keyword"}},
+		},
+		"track_total_hits": true,
+	}
+
+	res, err := search(ctx, "creators", searchRequest)
+	if err != nil {
+		// No creator has been stored yet
+		if isNotFound(res.Inspect().Response) {
+			return 0, []*models.Creator{}, nil
+		}
+		return 0, nil, storageError(res.Inspect().Response, err, "error listing creators")
+	}
+
+	creators := make([]*models.Creator, len(res.Hits.Hits))
+	for i, hit := range res.Hits.Hits {
+		var creator models.Creator
+		if err := json.Unmarshal(hit.Source, &creator); err != nil {
+			return 0, nil, err
+		}
+		creators[i] = &creator
+	}
+
+	return res.Hits.Total.Value, creators, nil
+}
+
+// SearchVideosByCreator returns the videos uploaded by the given creator, newest first.
+// Videos are matched on the stored creator ID and, for documents indexed before the
+// ID was recorded, on the channel link.
+func SearchVideosByCreator(ctx context.Context, creator *models.Creator, from int, size int) (int, []*models.Video, error) {
+	should := []interface{}{
+		map[string]interface{}{"term": map[string]interface{}{"creatorDetails.creatorId": creator.CreatorID}},
+	}
+	if creator.ChannelLink != "" {
+		should = append(should, map[string]interface{}{
+			"term": map[string]interface{}{"creatorDetails.channerlLink": creator.ChannelLink},
+		})
+	}
+
+	searchRequest := map[string]interface{}{
+		"from": from,
+		"size": size,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should":               should,
+				"minimum_should_match": 1,
+			},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"uploadDate": map[string]interface{}{"order": "desc", "unmapped_type": "date"}},
+		},
+		"track_total_hits": true,
+	}
+
+	res, err := search(ctx, "videos", searchRequest)
+	if err != nil {
+		return 0, nil, storageError(res.Inspect().Response, err, "error searching videos of creator %s", creator.CreatorID)
+	}
+
+	videos := make([]*models.Video, len(res.Hits.Hits))
+	for i, hit := range res.Hits.Hits {
+		var video models.Video
+		if err := json.Unmarshal(hit.Source, &video); err != nil {
+			return 0, nil, err
+		}
+		videos[i] = &video
+	}
+
+	return res.Hits.Total.Value, videos, nil
+}
+
+// search serializes the request body and runs it against a single index, or against the
+// point in time of the body when index is empty
+func search(ctx context.Context, index string, body map[string]interface{}) (*opensearchapi.SearchResp, error) {
+	searchData, err := json.Marshal(body)
+	if err != nil {
+		return &opensearchapi.SearchResp{}, err
+	}
+
+	req := &opensearchapi.SearchReq{Body: strings.NewReader(string(searchData))}
+	if index != "" {
+		req.Indices = []string{index}
+	}
+	return connect.Client.Search(ctx, req)
+}
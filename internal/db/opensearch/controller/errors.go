@@ -0,0 +1,24 @@
+package db
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/opensearch-project/opensearch-go/v4"
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+// storageError converts a failed OpenSearch call into a domain error. Connection failures,
+// cluster side 5xx/429 responses and 403 responses, which writes get while a migration
+// blocks them, are reported as storage unavailable.
+func storageError(resp *opensearch.Response, err error, format string, args ...interface{}) error {
+	if resp == nil || resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusForbidden {
+		return errs.Storage(err, format, args...)
+	}
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+}
+
+// isNotFound reports whether OpenSearch answered with 404
+func isNotFound(resp *opensearch.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// rawPage holds the parts of a search response the typed client drops: the sort values
+// and highlights of each hit and the point in time ID
+type rawPage struct {
+	PitID string `json:"pit_id"`
+	Hits  struct {
+		Hits []struct {
+			Sort      []interface{}       `json:"sort"`
+			Highlight map[string][]string `json:"highlight"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// pagedSearch runs a sorted search for one page. An offset page uses from and size, a
+// cursor page continues with search_after and the cursor's point in time. The first page
+// opens a point in time when asked to, and the last page closes it. Search errors are
+// returned unwrapped together with the response, like search does.
+func pagedSearch(ctx context.Context, index string, body map[string]interface{}, page models.Page, sort string) (*opensearchapi.SearchResp, *rawPage, *models.Cursor, error) {
+	pit := ""
+	body["size"] = page.Size
+	if page.Cursor != nil {
+		body["search_after"] = page.Cursor.After
+		pit = page.Cursor.PointInTime
+	} else {
+		body["from"] = page.From
+		if page.PointInTime && page.KeepAlive > 0 {
+			res, err := connect.Client.PointInTime.Create(ctx, opensearchapi.PointInTimeCreateReq{
+				Indices: []string{index},
+				Params:  opensearchapi.PointInTimeCreateParams{KeepAlive: page.KeepAlive},
+			})
+			if err != nil {
+				return &opensearchapi.SearchResp{}, nil, nil, storageError(res.Inspect().Response, err, "error opening point in time on %s", index)
+			}
+			pit = res.PitID
+		}
+	}
+
+	// A search on a point in time must not name the index
+	if pit != "" {
+		index = ""
+		pitBody := map[string]interface{}{"id": pit}
+		if page.KeepAlive > 0 {
+			pitBody["keep_alive"] = keepAlive(page.KeepAlive)
+		}
+		body["pit"] = pitBody
+	}
+
+	res, err := search(ctx, index, body)
+	if err != nil {
+		if pit != "" && isNotFound(res.Inspect().Response) {
+			return res, nil, nil, errs.Validation("the cursor has expired, start again from the first page")
+		}
+		return res, nil, nil, err
+	}
+
+	// Read the sort values as json.Number so that long values survive the round trip
+	var raw rawPage
+	decoder := json.NewDecoder(res.Inspect().Response.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return res, nil, nil, err
+	}
+	if raw.PitID == "" {
+		raw.PitID = pit
+	}
+
+	hits := raw.Hits.Hits
+	if len(hits) == 0 || len(hits) < page.Size || page.Cursor == nil && page.From+len(hits) >= res.Hits.Total.Value {
+		if raw.PitID != "" {
+			closePointInTime(ctx, raw.PitID)
+		}
+		return res, &raw, nil, nil
+	}
+	return res, &raw, &models.Cursor{After: hits[len(hits)-1].Sort, Sort: sort, PointInTime: raw.PitID}, nil
+}
+
+// closePointInTime releases a point in time that no cursor refers to anymore. Failures
+// are only logged since the point in time expires on its own.
+func closePointInTime(ctx context.Context, pit string) {
+	if _, err := connect.Client.PointInTime.Delete(ctx, opensearchapi.PointInTimeDeleteReq{PitID: []string{pit}}); err != nil {
+		lp.FromContext(ctx).Warn("closing point in time failed", "error", err)
+	}
+}
+
+// keepAlive formats a duration as an OpenSearch time value
+func keepAlive(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
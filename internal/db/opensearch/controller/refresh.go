@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// GetStaleVideos returns up to limit videos that were never refreshed or were last refreshed
+// before olderThan, oldest first.
+func GetStaleVideos(ctx context.Context, olderThan time.Time, limit int) ([]*models.Video, error) {
+	searchRequest := map[string]interface{}{
+		"size": limit,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []interface{}{
+					map[string]interface{}{
+						"bool": map[string]interface{}{
+							"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": "refreshedAt"}},
+						},
+					},
+					map[string]interface{}{
+						"range": map[string]interface{}{"refreshedAt": map[string]interface{}{"lt": olderThan.UTC().Format(time.RFC3339)}},
+					},
+				},
+				"minimum_should_match": 1,
+			},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"refreshedAt": map[string]interface{}{"order": "asc", "missing": "_first", "unmapped_type": "date"}},
+		},
+	}
+
+	res, err := search(ctx, "videos", searchRequest)
+	if err != nil {
+		return nil, storageError(res.Inspect().Response, err, "error searching stale videos")
+	}
+
+	videos := make([]*models.Video, len(res.Hits.Hits))
+	for i, hit := range res.Hits.Hits {
+		var video models.Video
+		if err := json.Unmarshal(hit.Source, &video); err != nil {
+			return nil, err
+		}
+		videos[i] = &video
+	}
+	return videos, nil
+}
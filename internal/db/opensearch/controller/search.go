@@ -0,0 +1,296 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+const (
+	// facetSize is the number of values returned per facet
+	facetSize = 20
+	// fragmentSize and fragmentCount bound the description highlights
+	fragmentSize  = 150
+	fragmentCount = 3
+)
+
+// searchFields are the fields matched by the query with typo tolerance
+var searchFields = []string{"title", "description", "tags", "categories"}
+
+// multilingualFields are the subfields analyzed with Arabic and Urdu normalization and
+// the Islamic terms synonyms, so that transliterations and script variants match
+var multilingualFields = []string{"title.multilingual", "description.multilingual", "categories.multilingual"}
+
+// SearchVideos runs a full text search with filters, sorting and pagination, and counts
+// the matching videos per category and creator. The category and creator filters are
+// applied as a post filter so that each facet can ignore its own filter. Each hit carries
+// the highlighted fragments of its title and description, taken from the multilingual
+// subfield when only a spelling variant matched. Pages continue through cursors as well as
+// offsets.
+func SearchVideos(ctx context.Context, req models.VideoSearch) (*models.VideoSearchResult, error) {
+	filters, err := baseFilters(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	byCategory := categoryFilters(req.Filters)
+	byCreator := creatorFilters(req.Filters)
+
+	searchRequest := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":   textQuery(req.Query, req.Match),
+				"filter": filters,
+			},
+		},
+		"highlight": map[string]interface{}{
+			"pre_tags":  []string{"<em>"},
+			"post_tags": []string{"</em>"},
+			"encoder":   "html",
+			"fields": map[string]interface{}{
+				"title":                    map[string]interface{}{"number_of_fragments": 0},
+				"title.multilingual":       map[string]interface{}{"number_of_fragments": 0},
+				"description":              map[string]interface{}{"fragment_size": fragmentSize, "number_of_fragments": fragmentCount},
+				"description.multilingual": map[string]interface{}{"fragment_size": fragmentSize, "number_of_fragments": fragmentCount},
+			},
+		},
+		"sort": sortClauses(req.Sort),
+		"aggs": map[string]interface{}{
+			"categories": map[string]interface{}{
+				"filter": allOf(byCreator),
+				"aggs": map[string]interface{}{
+					"values": map[string]interface{}{
+						"terms": map[string]interface{}{"field": "categories.keyword", "size": facetSize},
+					},
+				},
+			},
+			"creators": map[string]interface{}{
+				"filter": allOf(byCategory),
+				"aggs": map[string]interface{}{
+					"values": map[string]interface{}{
+						"terms": map[string]interface{}{"field": "creatorDetails.creatorId", "size": facetSize},
+						"aggs": map[string]interface{}{
+							"name": map[string]interface{}{
+								"terms": map[string]interface{}{"field": "creatorDetails.name.keyword", "size": 1},
+							},
+						},
+					},
+				},
+			},
+		},
+		"track_total_hits": true,
+	}
+	if len(byCategory) > 0 || len(byCreator) > 0 {
+		searchRequest["post_filter"] = allOf(append(append([]interface{}{}, byCategory...), byCreator...))
+	}
+
+	res, raw, next, err := pagedSearch(ctx, "videos", searchRequest, req.Page, req.Sort)
+	if err != nil {
+		return nil, storageError(res.Inspect().Response, err, "error searching videos")
+	}
+	lp.FromContext(ctx).Debug("searched videos", "query", req.Query, "hits", res.Hits.Total.Value)
+
+	result := &models.VideoSearchResult{
+		Total: res.Hits.Total.Value,
+		Hits:  make([]*models.VideoHit, len(res.Hits.Hits)),
+		Next:  next,
+	}
+	for i, hit := range res.Hits.Hits {
+		var video models.Video
+		if err := json.Unmarshal(hit.Source, &video); err != nil {
+			return nil, err
+		}
+		result.Hits[i] = &models.VideoHit{Video: &video}
+		if i < len(raw.Hits.Hits) {
+			result.Hits[i].Highlights = mergeHighlights(raw.Hits.Hits[i].Highlight)
+		}
+	}
+
+	type bucket struct {
+		Key      string `json:"key"`
+		DocCount int    `json:"doc_count"`
+		Name     struct {
+			Buckets []struct {
+				Key string `json:"key"`
+			} `json:"buckets"`
+		} `json:"name"`
+	}
+	var aggs struct {
+		Categories struct {
+			Values struct {
+				Buckets []bucket `json:"buckets"`
+			} `json:"values"`
+		} `json:"categories"`
+		Creators struct {
+			Values struct {
+				Buckets []bucket `json:"buckets"`
+			} `json:"values"`
+		} `json:"creators"`
+	}
+	if len(res.Aggregations) > 0 {
+		if err := json.Unmarshal(res.Aggregations, &aggs); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Facets.Categories = make([]models.CategoryCount, len(aggs.Categories.Values.Buckets))
+	for i, b := range aggs.Categories.Values.Buckets {
+		result.Facets.Categories[i] = models.CategoryCount{Name: b.Key, Count: b.DocCount}
+	}
+	result.Facets.Creators = make([]models.CreatorCount, len(aggs.Creators.Values.Buckets))
+	for i, b := range aggs.Creators.Values.Buckets {
+		result.Facets.Creators[i] = models.CreatorCount{CreatorID: b.Key, Count: b.DocCount}
+		if len(b.Name.Buckets) > 0 {
+			result.Facets.Creators[i].Name = b.Name.Buckets[0].Key
+		}
+	}
+	return result, nil
+}
+
+// textQuery matches the query against the search fields with the configured typo
+// tolerance and against the multilingual subfields, scoring each video by the better of
+// the two. Titles that contain the query as a phrase score higher. An empty query matches
+// every video.
+func textQuery(query string, match models.TextMatch) map[string]interface{} {
+	if query == "" {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+
+	multiMatch := map[string]interface{}{
+		"query":  query,
+		"fields": searchFields,
+	}
+	if match.Fuzziness != "" && match.Fuzziness != "0" {
+		multiMatch["fuzziness"] = match.Fuzziness
+		multiMatch["prefix_length"] = match.PrefixLength
+	}
+
+	// Fuzziness stays off the multilingual subfields, whose synonyms already cover the
+	// spelling variants
+	variants := map[string]interface{}{
+		"query":  query,
+		"fields": multilingualFields,
+	}
+
+	should := []interface{}{}
+	if match.PhraseBoost > 0 {
+		should = append(should, map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  query,
+				"type":   "phrase",
+				"fields": []string{"title", "title.multilingual"},
+				"boost":  match.PhraseBoost,
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must": map[string]interface{}{
+				"dis_max": map[string]interface{}{
+					"queries": []interface{}{
+						map[string]interface{}{"multi_match": multiMatch},
+						map[string]interface{}{"multi_match": variants},
+					},
+				},
+			},
+			"should": should,
+		},
+	}
+}
+
+// mergeHighlights reports the fragments of the multilingual subfields under their parent
+// field, preferring the fragments of the parent itself
+func mergeHighlights(fields map[string][]string) map[string][]string {
+	if len(fields) == 0 {
+		return nil
+	}
+	merged := make(map[string][]string, len(fields))
+	for field, fragments := range fields {
+		parent, isSubfield := strings.CutSuffix(field, ".multilingual")
+		if _, ok := fields[parent]; isSubfield && ok {
+			continue
+		}
+		merged[parent] = fragments
+	}
+	return merged
+}
+
+// baseFilters returns the filters that apply to the hits and to every facet
+func baseFilters(f models.SearchFilters) ([]interface{}, error) {
+	filters := []interface{}{}
+	if f.IsShort != nil {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"isShort": *f.IsShort}})
+	}
+	from, until, err := f.UploadBounds()
+	if err != nil {
+		return nil, err
+	}
+	if !from.IsZero() || !until.IsZero() {
+		bounds := map[string]interface{}{}
+		if !from.IsZero() {
+			bounds["gte"] = from.UTC().Format(time.RFC3339Nano)
+		}
+		if !until.IsZero() {
+			bounds["lt"] = until.UTC().Format(time.RFC3339Nano)
+		}
+		filters = append(filters, map[string]interface{}{"range": map[string]interface{}{"uploadDate": bounds}})
+	}
+	if f.MinViews > 0 {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"viewsCount": map[string]interface{}{"gte": f.MinViews}},
+		})
+	}
+	return filters, nil
+}
+
+// categoryFilters matches the categories filters exactly against categories.keyword
+func categoryFilters(f models.SearchFilters) []interface{} {
+	filters := []interface{}{}
+	if len(f.CategoriesAny) > 0 {
+		filters = append(filters, map[string]interface{}{"terms": map[string]interface{}{"categories.keyword": f.CategoriesAny}})
+	}
+	for _, category := range f.CategoriesAll {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"categories.keyword": category}})
+	}
+	return filters
+}
+
+// creatorFilters matches the creator filter
+func creatorFilters(f models.SearchFilters) []interface{} {
+	if f.CreatorID == "" {
+		return []interface{}{}
+	}
+	return []interface{}{map[string]interface{}{"term": map[string]interface{}{"creatorDetails.creatorId": f.CreatorID}}}
+}
+
+// allOf combines filters into one bool filter, matching everything when there are none
+func allOf(filters []interface{}) map[string]interface{} {
+	return map[string]interface{}{"bool": map[string]interface{}{"filter": filters}}
+}
+
+// sortClauses returns the sort of a search order. Ties are broken by upload date and
+// then video ID so pages are stable.
+func sortClauses(order string) []interface{} {
+	newest := map[string]interface{}{"uploadDate": map[string]interface{}{"order": "desc", "unmapped_type": "date"}}
+	byID := map[string]interface{}{"videoId": map[string]interface{}{"order": "asc"}}
+
+	switch order {
+	case models.SortNewest:
+		return []interface{}{newest, byID}
+	case models.SortMostViewed:
+		return []interface{}{
+			map[string]interface{}{"viewsCount": map[string]interface{}{"order": "desc", "unmapped_type": "long"}},
+			newest, byID,
+		}
+	case models.SortMostLiked:
+		return []interface{}{
+			map[string]interface{}{"likes": map[string]interface{}{"order": "desc", "unmapped_type": "long"}},
+			newest, byID,
+		}
+	}
+	return []interface{}{"_score", newest, byID}
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// GetVideoStats counts all videos and the videos of the 1000 largest categories
+func GetVideoStats(ctx context.Context) (*models.VideoStats, error) {
+	searchRequest := map[string]interface{}{
+		"size":             0,
+		"track_total_hits": true,
+		"aggs": map[string]interface{}{
+			"categories": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "categories.keyword",
+					"size":  1000,
+				},
+			},
+		},
+	}
+
+	res, err := search(ctx, "videos", searchRequest)
+	if err != nil {
+		return nil, storageError(res.Inspect().Response, err, "error collecting video stats")
+	}
+
+	var aggs struct {
+		Categories struct {
+			Buckets []struct {
+				Key      string `json:"key"`
+				DocCount int    `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"categories"`
+	}
+	if len(res.Aggregations) > 0 {
+		if err := json.Unmarshal(res.Aggregations, &aggs); err != nil {
+			return nil, err
+		}
+	}
+
+	stats := &models.VideoStats{
+		Total:      res.Hits.Total.Value,
+		Categories: make([]models.CategoryCount, len(aggs.Categories.Buckets)),
+	}
+	for i, bucket := range aggs.Categories.Buckets {
+		stats.Categories[i] = models.CategoryCount{Name: bucket.Key, Count: bucket.DocCount}
+	}
+	return stats, nil
+}
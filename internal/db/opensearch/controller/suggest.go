@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// suggestCandidates is the number of titles, creators and categories fetched per group
+// before duplicates and non-matching categories are dropped
+const suggestCandidates = 50
+
+// Suggest completes the typed query to video titles, creators and categories in a single
+// request. Each group is an aggregation filtered by a bool_prefix query on the matching
+// search_as_you_type field. Titles are ordered by views and deduplicated; creators and
+// categories by their number of videos.
+func Suggest(ctx context.Context, query string, size int) (*models.Suggestions, error) {
+	titles := prefixQuery(query, "title.suggest")
+	creators := prefixQuery(query, "creatorDetails.name.suggest")
+	categories := prefixQuery(query, "categories.suggest")
+
+	searchRequest := map[string]interface{}{
+		"size": 0,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should":               []interface{}{titles, creators, categories},
+				"minimum_should_match": 1,
+			},
+		},
+		"aggs": map[string]interface{}{
+			"titles": map[string]interface{}{
+				"filter": titles,
+				"aggs": map[string]interface{}{
+					"top": map[string]interface{}{
+						"top_hits": map[string]interface{}{
+							"size":    suggestCandidates,
+							"_source": []string{"videoId", "title"},
+							"sort": []interface{}{
+								map[string]interface{}{"viewsCount": map[string]interface{}{"order": "desc", "unmapped_type": "long"}},
+								map[string]interface{}{"videoId": map[string]interface{}{"order": "asc"}},
+							},
+						},
+					},
+				},
+			},
+			"creators": map[string]interface{}{
+				"filter": creators,
+				"aggs": map[string]interface{}{
+					"values": map[string]interface{}{
+						"terms": map[string]interface{}{"field": "creatorDetails.creatorId", "size": size},
+						"aggs": map[string]interface{}{
+							"name": map[string]interface{}{
+								"terms": map[string]interface{}{"field": "creatorDetails.name.keyword", "size": 1},
+							},
+						},
+					},
+				},
+			},
+			"categories": map[string]interface{}{
+				"filter": categories,
+				"aggs": map[string]interface{}{
+					"values": map[string]interface{}{
+						"terms": map[string]interface{}{"field": "categories.keyword", "size": suggestCandidates},
+					},
+				},
+			},
+		},
+	}
+
+	res, err := search(ctx, "videos", searchRequest)
+	if err != nil {
+		return nil, storageError(res.Inspect().Response, err, "error suggesting completions")
+	}
+
+	type bucket struct {
+		Key      string `json:"key"`
+		DocCount int    `json:"doc_count"`
+		Name     struct {
+			Buckets []struct {
+				Key string `json:"key"`
+			} `json:"buckets"`
+		} `json:"name"`
+	}
+	var aggs struct {
+		Titles struct {
+			Top struct {
+				Hits struct {
+					Hits []struct {
+						Source struct {
+							VideoID string `json:"videoId"`
+							Title   string `json:"title"`
+						} `json:"_source"`
+					} `json:"hits"`
+				} `json:"hits"`
+			} `json:"top"`
+		} `json:"titles"`
+		Creators struct {
+			Values struct {
+				Buckets []bucket `json:"buckets"`
+			} `json:"values"`
+		} `json:"creators"`
+		Categories struct {
+			Values struct {
+				Buckets []bucket `json:"buckets"`
+			} `json:"values"`
+		} `json:"categories"`
+	}
+	if len(res.Aggregations) > 0 {
+		if err := json.Unmarshal(res.Aggregations, &aggs); err != nil {
+			return nil, err
+		}
+	}
+
+	suggestions := &models.Suggestions{
+		Titles:     []models.Suggestion{},
+		Creators:   []models.Suggestion{},
+		Categories: []models.Suggestion{},
+	}
+	seen := map[string]bool{}
+	for _, hit := range aggs.Titles.Top.Hits.Hits {
+		title := strings.TrimSpace(hit.Source.Title)
+		if len(suggestions.Titles) == size || title == "" || seen[strings.ToLower(title)] {
+			continue
+		}
+		seen[strings.ToLower(title)] = true
+		suggestions.Titles = append(suggestions.Titles, models.Suggestion{Text: title, VideoID: hit.Source.VideoID})
+	}
+	for _, b := range aggs.Creators.Values.Buckets {
+		suggestion := models.Suggestion{Text: b.Key, CreatorID: b.Key, Count: b.DocCount}
+		if len(b.Name.Buckets) > 0 {
+			suggestion.Text = b.Name.Buckets[0].Key
+		}
+		suggestions.Creators = append(suggestions.Creators, suggestion)
+	}
+	// The buckets hold every category of the matching videos, keep the matching ones
+	for _, b := range aggs.Categories.Values.Buckets {
+		if len(suggestions.Categories) < size && models.MatchesPrefix(b.Key, query) {
+			suggestions.Categories = append(suggestions.Categories, models.Suggestion{Text: b.Key, Count: b.DocCount})
+		}
+	}
+	return suggestions, nil
+}
+
+// prefixQuery matches the typed words against a search_as_you_type field, the last word
+// as a prefix
+func prefixQuery(query string, field string) map[string]interface{} {
+	return map[string]interface{}{
+		"multi_match": map[string]interface{}{
+			"query":    query,
+			"type":     "bool_prefix",
+			"operator": "and",
+			"fields":   []string{field, field + "._2gram", field + "._3gram"},
+		},
+	}
+}
@@ -4,298 +4,63 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
 	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/errs"
 	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
 )
 
-func InsertOrUpdateVideo(video *models.Video) error {
-	// Serialize video object to JSON
-	data, err := json.Marshal(video)
-	if err != nil {
-		return err
-	}
-
-	// Create request to index document in OpenSearch
-	req := opensearchapi.IndexReq{
-		Index:      "videos",
-		DocumentID: video.VideoID,
-		Body:       strings.NewReader(string(data)),
-		Params: opensearchapi.IndexParams{
-			Refresh: "true",
-		},
-		// Refresh:    "true", // Refresh index after operation
-	}
-
-	insertResp, err := connect.Client.Index(context.Background(), req)
-	if err != nil {
-		return err
-	}
-	fmt.Printf("Created document in %s\n  ID: %s\n", insertResp.Index, insertResp.ID)
-
-	// Execute request
-	// res, err := req.Do(context.Background(), connect.Client)
-	// if err != nil {
-	// 	return err
-	// }
-	// defer res.Body.Close()
-
-	// if res.IsError() {
-	// 	// Handle error response
-	// 	return fmt.Errorf("failed to index document: %s", res.String())
-	// }
-
-	return nil
-}
-
-// SearchVideos queries the OpenSearch index for videos matching the query with pagination
-func SearchVideos(query string, from int, size int) (int, []*models.Video, error) {
-	// Create search request with pagination
-	searchRequest := map[string]interface{}{
-		"from": from,
-		"size": size,
-		"query": map[string]interface{}{
-			"multi_match": map[string]interface{}{
-				"query":  query,
-				"fields": []string{"title", "description", "tags", "categories"},
-			},
-		},
-		"track_total_hits": true, // Ensure total hits is tracked
-	}
-
-	// Serialize search request to JSON
-	searchData, err := json.Marshal(searchRequest)
-	if err != nil {
-		return 0, nil, err
-	}
-
-	// Perform search request
-	searchReq := strings.NewReader(string(searchData))
-	// Perform search request
-	res, err := connect.Client.Search(
-		context.Background(),
-		&opensearchapi.SearchReq{
-			Indices: []string{"videos"},
-			Body:    searchReq,
-		},
-	)
-	if err != nil {
-		return 0, nil, err
-	}
-	fmt.Printf("Search hits: %v\n", res.Hits.Total.Value)
-
-	// Check if the search response is an error
-	if res.Inspect().Response.IsError() {
-		return 0, nil, fmt.Errorf("search response error: %v", res.Errors)
-	}
-
-	// Parse search response
-	var searchResponse map[string]interface{}
-	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&searchResponse); err != nil {
-		return 0, nil, err
-	}
-
-	// Extract video results from search response
-	hits := searchResponse["hits"].(map[string]interface{})["hits"].([]interface{})
-	videos := make([]*models.Video, len(hits))
-	for i, hit := range hits {
-		videoData := hit.(map[string]interface{})["_source"]
-		videoBytes, _ := json.Marshal(videoData)
-		var video models.Video
-		if err := json.Unmarshal(videoBytes, &video); err != nil {
-			return 0, nil, err
-		}
-		videos[i] = &video
-	}
-	// total := searchResponse["hits"].(map[string]interface{})["total"].(map[string]interface{})["value"].(int),
-	// Extract total hits count
-	totalHits := searchResponse["hits"].(map[string]interface{})["total"]
-	var total int
-	switch totalHits := totalHits.(type) {
-	case map[string]interface{}:
-		total = int(totalHits["value"].(float64))
-	case float64:
-		total = int(totalHits)
-	}
-	return total, videos, nil
-}
-
-func GetAllCategories() ([]string, error) {
-	// Create a search request to get all categories
-	searchRequest := map[string]interface{}{
-		"size": 0,
-		"aggs": map[string]interface{}{
-			"unique_categories": map[string]interface{}{
-				"terms": map[string]interface{}{
-					"field": "categories.keyword",
-					"size":  1000,
+// SearchVideosByCategory queries the OpenSearch index for videos tagged with any of the
+// categories with pagination. The slugs match categories.keyword exactly, so that salah
+// leaves fiqh-salah out. Hits are ordered by the number of matching categories with the
+// upload date and video ID breaking ties, so that cursors can continue after any hit.
+func SearchVideosByCategory(ctx context.Context, categories []string, page models.Page) (*models.VideoPage, error) {
+	matches := make([]interface{}, len(categories))
+	for i, category := range categories {
+		matches[i] = map[string]interface{}{
+			"constant_score": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"term": map[string]interface{}{"categories.keyword": category},
 				},
 			},
-		},
-	}
-
-	// Serialize search request to JSON
-	searchData, err := json.Marshal(searchRequest)
-	if err != nil {
-		return nil, err
-	}
-
-	// Perform search request
-	res, err := connect.Client.Search(
-		context.Background(),
-		&opensearchapi.SearchReq{
-			Indices: []string{"videos"},
-			Body:    strings.NewReader(string(searchData)),
-		},
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	// Check if the search response is an error
-	if res.Inspect().Response.IsError() {
-		return nil, fmt.Errorf("search response error: %v", res.Errors)
-	}
-
-	// Parse search response
-	var searchResponse map[string]interface{}
-	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&searchResponse); err != nil {
-		return nil, err
-	}
-
-	// Extract unique categories from search response
-	buckets := searchResponse["aggregations"].(map[string]interface{})["unique_categories"].(map[string]interface{})["buckets"].([]interface{})
-	categories := make([]string, len(buckets))
-	for i, bucket := range buckets {
-		categories[i] = bucket.(map[string]interface{})["key"].(string)
+		}
 	}
 
-	return categories, nil
-}
-
-// SearchVideosByCategory queries the OpenSearch index for videos matching the category with pagination
-func SearchVideosByCategory(category string, from int, size int) (int, []*models.Video, error) {
-	// Create search request with pagination
+	// Create search request, pagination is added by pagedSearch
 	searchRequest := map[string]interface{}{
-		"from": from,
-		"size": size,
 		"query": map[string]interface{}{
-			"match": map[string]interface{}{
-				"categories": category,
+			"bool": map[string]interface{}{
+				"should":               matches,
+				"minimum_should_match": 1,
 			},
 		},
+		"sort":             sortClauses(models.SortRelevance),
 		"track_total_hits": true, // Ensure total hits is tracked
 	}
 
-	// Serialize search request to JSON
-	searchData, err := json.Marshal(searchRequest)
-	if err != nil {
-		return 0, nil, err
-	}
-
 	// Perform search request
-	searchReq := strings.NewReader(string(searchData))
-
-	// Perform search request
-	res, err := connect.Client.Search(
-		context.Background(),
-		&opensearchapi.SearchReq{
-			Indices: []string{"videos"},
-			Body:    searchReq,
-		},
-	)
+	res, _, next, err := pagedSearch(ctx, "videos", searchRequest, page, models.SortRelevance)
 	if err != nil {
-		return 0, nil, err
-	}
-
-	// Check if the search response is an error
-	if res.Inspect().Response.IsError() {
-		return 0, nil, fmt.Errorf("search response error: %v", res.Errors)
-	}
-
-	// Parse search response
-	var searchResponse map[string]interface{}
-	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&searchResponse); err != nil {
-		return 0, nil, err
+		return nil, storageError(res.Inspect().Response, err, "error searching videos by category %s", strings.Join(categories, ", "))
 	}
 
 	// Extract video results from search response
-	hits := searchResponse["hits"].(map[string]interface{})["hits"].([]interface{})
-	videos := make([]*models.Video, len(hits))
-	for i, hit := range hits {
-		videoData := hit.(map[string]interface{})["_source"]
-		videoBytes, _ := json.Marshal(videoData)
+	videos := make([]*models.Video, len(res.Hits.Hits))
+	for i, hit := range res.Hits.Hits {
 		var video models.Video
-		if err := json.Unmarshal(videoBytes, &video); err != nil {
-			return 0, nil, err
+		if err := json.Unmarshal(hit.Source, &video); err != nil {
+			return nil, err
 		}
 		videos[i] = &video
 	}
 
-	// Extract total hits count
-	totalHits := searchResponse["hits"].(map[string]interface{})["total"]
-	var total int
-	switch totalHits := totalHits.(type) {
-	case map[string]interface{}:
-		total = int(totalHits["value"].(float64))
-	case float64:
-		total = int(totalHits)
-	}
-
-	return total, videos, nil
-}
-
-func DeleteVideo(videoID string) error {
-	// Create delete request
-	req := opensearchapi.DocumentDeleteReq{
-		Index:      "videos",
-		DocumentID: videoID,
-		Params: opensearchapi.DocumentDeleteParams{
-			Refresh: "true",
-		}}
-	deleteResponse, err := connect.Client.Document.Delete(context.Background(), req)
-	if err != nil {
-		return err
-	}
-	// Execute request
-	// res, err := req.Do(context.Background(), connect.Client)
-	// if err != nil {
-	// 	return err
-	// }
-	// defer res.Body.Close()
-
-	// if res.IsError() {
-	// 	// Handle error response
-	// 	return fmt.Errorf("failed to delete document: %s", res.String())
-	// }
-	fmt.Printf("Deleted document: %t\n", deleteResponse.Result == "deleted")
-
-	return nil
+	return &models.VideoPage{Total: res.Hits.Total.Value, Videos: videos, Next: next}, nil
 }
 
-func GetVideoByID(videoID string) (*models.Video, error) {
-	ctx := context.Background()
-	// Check if the "videos" index exists in the OpenSearch cluster
-	indexExists, err := connect.Client.Indices.Exists(ctx, opensearchapi.IndicesExistsReq{
-		Indices: []string{"videos"},
-	})
-	if err != nil && indexExists.StatusCode != 404 {
-		return nil, fmt.Errorf("error checking if index exists: %w", err)
-	}
-
-	if indexExists.StatusCode != 200 {
-		_, err := connect.Client.Indices.Create(ctx, opensearchapi.IndicesCreateReq{
-			Index: "videos",
-			Body:  strings.NewReader(""),
-		})
-		if err != nil {
-			return nil, fmt.Errorf("error while creating index")
-		}
-	}
-
+func GetVideoByID(ctx context.Context, videoID string) (*models.Video, error) {
 	// Create a request to retrieve the document by ID
 	req := opensearchapi.DocumentGetReq{
 		Index:      "videos",
@@ -303,14 +68,12 @@ func GetVideoByID(videoID string) (*models.Video, error) {
 	}
 
 	getResponse, err := connect.Client.Document.Get(ctx, req)
-	if err != nil {
-		return nil, err
-	}
 	// Check if the document exists
-	if getResponse.Inspect().Response.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("video with ID %s not found", videoID)
-	} else if getResponse.Inspect().Response.IsError() {
-		return nil, fmt.Errorf("error getting video with ID %s: %s", videoID, getResponse.Inspect().Response.Status())
+	if isNotFound(getResponse.Inspect().Response) {
+		return nil, errs.NotFound("video", videoID)
+	}
+	if err != nil {
+		return nil, storageError(getResponse.Inspect().Response, err, "error getting video with ID %s", videoID)
 	}
 
 	// Parse the response body into a video object
@@ -323,7 +86,40 @@ func GetVideoByID(videoID string) (*models.Video, error) {
 	return &video, nil
 }
 
-func DeleteVideoByID(videoID string) error {
+// GetVideosByIDs retrieves several videos in one multi get request, keyed by ID. Missing
+// videos are left out.
+func GetVideosByIDs(ctx context.Context, videoIDs []string) (map[string]*models.Video, error) {
+	videos := make(map[string]*models.Video, len(videoIDs))
+	if len(videoIDs) == 0 {
+		return videos, nil
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"ids": videoIDs})
+	if err != nil {
+		return nil, err
+	}
+	getResponse, err := connect.Client.MGet(ctx, opensearchapi.MGetReq{
+		Index: "videos",
+		Body:  strings.NewReader(string(data)),
+	})
+	if err != nil {
+		return nil, storageError(getResponse.Inspect().Response, err, "error getting %d videos", len(videoIDs))
+	}
+
+	for _, doc := range getResponse.Docs {
+		if !doc.Found {
+			continue
+		}
+		var video models.Video
+		if err := json.Unmarshal(doc.Source, &video); err != nil {
+			return nil, fmt.Errorf("error decoding video %s: %s", doc.ID, err)
+		}
+		videos[doc.ID] = &video
+	}
+	return videos, nil
+}
+
+func DeleteVideoByID(ctx context.Context, videoID string) error {
 	// Create delete request
 	req := opensearchapi.DocumentDeleteReq{
 		Index:      "videos",
@@ -333,23 +129,20 @@ func DeleteVideoByID(videoID string) error {
 		},
 	}
 
-	deleteResponse, err := connect.Client.Document.Delete(context.Background(), req)
-	if err != nil {
-		return err
-	}
-
+	deleteResponse, err := connect.Client.Document.Delete(ctx, req)
 	// Check if the delete operation was successful
-	if deleteResponse.Inspect().Response.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("video with ID %s not found", videoID)
-	} else if deleteResponse.Inspect().Response.IsError() {
-		return fmt.Errorf("error deleting video with ID %s: %s", videoID, deleteResponse.Inspect().Response.Status())
+	if isNotFound(deleteResponse.Inspect().Response) {
+		return errs.NotFound("video", videoID)
+	}
+	if err != nil {
+		return storageError(deleteResponse.Inspect().Response, err, "error deleting video with ID %s", videoID)
 	}
 
-	fmt.Printf("Deleted document with ID %s\n", videoID)
+	lp.FromContext(ctx).Debug("deleted video", "video_id", videoID)
 	return nil
 }
 
-func InsertVideo(videos *models.Video) error {
+func InsertVideo(ctx context.Context, videos *models.Video) error {
 	// Serialize video object to JSON
 	data, err := json.Marshal(videos)
 	if err != nil {
@@ -367,11 +160,11 @@ func InsertVideo(videos *models.Video) error {
 	}
 
 	// Execute request
-	insertResp, err := connect.Client.Index(context.Background(), req)
+	insertResp, err := connect.Client.Index(ctx, req)
 	if err != nil {
-		return err
+		return storageError(insertResp.Inspect().Response, err, "error inserting video %s", videos.VideoID)
 	}
-	fmt.Printf("Created document in %s\n  ID: %s\n", insertResp.Index, insertResp.ID)
+	lp.FromContext(ctx).Debug("inserted video", "index", insertResp.Index, "video_id", insertResp.ID)
 
 	if insertResp.Inspect().Response.IsError() {
 		// Handle error response
@@ -381,7 +174,7 @@ func InsertVideo(videos *models.Video) error {
 	return nil
 }
 
-func UpdateVideo(video *models.Video) error {
+func UpdateVideo(ctx context.Context, video *models.Video) error {
 	// Serialize video object to JSON
 	data, err := json.Marshal(video)
 	if err != nil {
@@ -399,16 +192,11 @@ func UpdateVideo(video *models.Video) error {
 	}
 
 	// Execute request
-	// res, err := req.Do(context.Background(), connect.Client)
-	// if err != nil {
-	// 	return err
-	// }
-	// defer res.Body.Close()
-	updateResp, err := connect.Client.Index(context.Background(), req)
+	updateResp, err := connect.Client.Index(ctx, req)
 	if err != nil {
-		return err
+		return storageError(updateResp.Inspect().Response, err, "error updating video %s", video.VideoID)
 	}
-	fmt.Printf("Created document in %s\n  ID: %s\n", updateResp.Index, updateResp.ID)
+	lp.FromContext(ctx).Debug("updated video", "index", updateResp.Index, "video_id", updateResp.ID)
 
 	if updateResp.Inspect().Response.IsError() {
 		// Handle error response
@@ -417,3 +205,40 @@ func UpdateVideo(video *models.Video) error {
 
 	return nil
 }
+
+// UpdateVideoMetadata writes every field of the video but its categories with a partial
+// update, so category changes made since the video was read are kept. It fails with
+// errs.ErrNotFound rather than recreating a video deleted meanwhile.
+func UpdateVideoMetadata(ctx context.Context, video *models.Video) error {
+	data, err := json.Marshal(video)
+	if err != nil {
+		return err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	delete(doc, "categories")
+	data, err = json.Marshal(map[string]interface{}{"doc": doc})
+	if err != nil {
+		return err
+	}
+
+	res, err := connect.Client.Update(ctx, opensearchapi.UpdateReq{
+		Index:      "videos",
+		DocumentID: video.VideoID,
+		Body:       strings.NewReader(string(data)),
+		Params: opensearchapi.UpdateParams{
+			Refresh:         "true",
+			RetryOnConflict: opensearchapi.ToPointer(3),
+		},
+	})
+	if isNotFound(res.Inspect().Response) {
+		return errs.NotFound("video", video.VideoID)
+	}
+	if err != nil {
+		return storageError(res.Inspect().Response, err, "error updating metadata of video %s", video.VideoID)
+	}
+	lp.FromContext(ctx).Debug("updated video metadata", "video_id", video.VideoID, "result", res.Result)
+	return nil
+}
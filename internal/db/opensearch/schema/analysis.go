@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// SynonymsPath is the synonyms file read by the search analyzer of the videos index,
+// relative to the config directory of each OpenSearch node. Every node needs a copy:
+// creating the index, or allocating one of its shards, fails on a node without it.
+const SynonymsPath = "analysis/islamic_synonyms.txt"
+
+// analyzeSynonymsReq runs the synonym filter reading SynonymsPath over a sample text. The
+// typed analyze request only takes filter names, not filter definitions.
+type analyzeSynonymsReq struct{}
+
+func (r analyzeSynonymsReq) GetRequest() (*http.Request, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"tokenizer": "standard",
+		"filter":    []interface{}{map[string]interface{}{"type": "synonym_graph", "synonyms_path": SynonymsPath}},
+		"text":      "salah",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return opensearch.BuildRequest(http.MethodPost, "/_analyze", bytes.NewReader(body), nil, http.Header{"Content-Type": {"application/json"}})
+}
+
+// CheckSynonyms reports a clear error when the node answering cannot read the synonyms
+// file. Only that node is checked, so the file still has to be installed on every node.
+func CheckSynonyms(ctx context.Context, client *opensearchapi.Client) error {
+	resp, err := client.Client.Do(ctx, analyzeSynonymsReq{}, nil)
+	if err != nil {
+		return fmt.Errorf("checking the synonyms file: %w", err)
+	}
+	if resp.IsError() {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("OpenSearch cannot read the synonyms file %s from its config directory, "+
+			"copy config/opensearch/islamic_synonyms.txt there on every node: status %d: %s", SynonymsPath, resp.StatusCode, body)
+	}
+	return nil
+}
+
+// refreshAnalyzersReq asks the index management plugin to reload the updateable search
+// analyzers of an index, which the typed client does not cover
+type refreshAnalyzersReq struct {
+	Index string
+}
+
+func (r refreshAnalyzersReq) GetRequest() (*http.Request, error) {
+	return opensearch.BuildRequest(http.MethodPost, "/_plugins/_refresh_search_analyzers/"+r.Index, nil, nil, nil)
+}
+
+// ReloadSynonyms makes the videos index pick up changes to the synonyms file read by its
+// search analyzer. The file must already be updated on every node.
+func ReloadSynonyms(ctx context.Context, client *opensearchapi.Client) error {
+	var result struct {
+		Shards struct {
+			Total      int `json:"total"`
+			Successful int `json:"successful"`
+			Failed     int `json:"failed"`
+		} `json:"_shards"`
+	}
+	resp, err := client.Client.Do(ctx, refreshAnalyzersReq{Index: Videos.Alias}, &result)
+	if err != nil {
+		return fmt.Errorf("reloading search analyzers of %s: %w", Videos.Alias, err)
+	}
+	if resp.IsError() {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("reloading search analyzers of %s: status %d: %s", Videos.Alias, resp.StatusCode, body)
+	}
+	if result.Shards.Failed > 0 {
+		return fmt.Errorf("reloading search analyzers of %s: %d of %d shards failed", Videos.Alias, result.Shards.Failed, result.Shards.Total)
+	}
+	lp.FromContext(ctx).Info("reloaded search analyzers", "alias", Videos.Alias, "shards", result.Shards.Successful)
+	return nil
+}
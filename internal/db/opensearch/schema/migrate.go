@@ -0,0 +1,343 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// callTimeout bounds the single calls of a migration that may outlive its context or
+// would not be bounded otherwise: lifting the write block, and checking or canceling a
+// reindex task
+const callTimeout = 30 * time.Second
+
+// MigrateOptions controls how Migrate applies the index definitions
+type MigrateOptions struct {
+	// DryRun only logs the planned steps
+	DryRun bool
+	// DeleteOld removes the previous versioned index once the alias has moved
+	DeleteOld bool
+}
+
+// Migrate brings every managed index up to its current version. It first checks that the
+// synonyms file needed by the videos index is installed.
+func Migrate(ctx context.Context, client *opensearchapi.Client, opts MigrateOptions) error {
+	if err := CheckSynonyms(ctx, client); err != nil {
+		return err
+	}
+	for _, index := range All() {
+		if err := migrateIndex(ctx, client, index, opts); err != nil {
+			return fmt.Errorf("migrating %s: %w", index.Alias, err)
+		}
+	}
+	return nil
+}
+
+// migrateIndex creates <alias>_v<version>, copies the documents from whatever the alias
+// currently points at and atomically swaps the alias, so readers never see a missing index.
+// A concrete index carrying the alias name, left behind by dynamic index creation, is
+// reindexed and replaced by the alias in the same way.
+//
+// The sources are write blocked for the copy so that no change made meanwhile is lost:
+// inserts, updates and deletes sent through the alias fail until it has moved, while
+// reads keep being served. The block is lifted from the sources that are kept, whether
+// the migration succeeds, fails or is canceled.
+func migrateIndex(ctx context.Context, client *opensearchapi.Client, index Index, opts MigrateOptions) (err error) {
+	target := index.Name()
+
+	current, legacy, err := resolveAlias(ctx, client, index.Alias)
+	if err != nil {
+		return err
+	}
+	for _, name := range current {
+		if versionOf(name) > index.Version {
+			return fmt.Errorf("alias %s points to %s which is newer than %s", index.Alias, name, target)
+		}
+	}
+	if len(current) == 1 && current[0] == target {
+		lp.FromContext(ctx).Info("index is up to date", "alias", index.Alias, "index", target)
+		return nil
+	}
+
+	sources := []string{}
+	served := false
+	for _, name := range current {
+		if name == target {
+			served = true
+		} else {
+			sources = append(sources, name)
+		}
+	}
+	if legacy {
+		sources = []string{index.Alias}
+	}
+	if opts.DryRun {
+		lp.FromContext(ctx).Info("dry run: would block writes, create index, reindex and move the alias", "alias", index.Alias, "index", target, "sources", sources)
+		return nil
+	}
+
+	// A target left behind by an interrupted run may miss deletions made since, start over
+	// unless the alias already serves it
+	if err := createIndex(ctx, client, index, !served); err != nil {
+		return err
+	}
+
+	blocked := sources
+	defer func() {
+		// Lift the block even when ctx has been canceled, say by a shutdown during the copy
+		unblockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), callTimeout)
+		defer cancel()
+		if unblockErr := blockWrites(unblockCtx, client, blocked, false); unblockErr != nil {
+			lp.FromContext(ctx).Error("failed to lift the write block, clear index.blocks.write by hand", "indices", blocked, "error", unblockErr)
+			if err == nil {
+				err = unblockErr
+			}
+		}
+	}()
+	if err := blockWrites(ctx, client, sources, true); err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		if err := reindex(ctx, client, source, target); err != nil {
+			return err
+		}
+	}
+
+	actions := []map[string]interface{}{}
+	if legacy {
+		actions = append(actions, map[string]interface{}{"remove_index": map[string]interface{}{"index": index.Alias}})
+	} else {
+		for _, name := range sources {
+			actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": name, "alias": index.Alias}})
+		}
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]interface{}{"index": target, "alias": index.Alias, "is_write_index": true},
+	})
+	if err := updateAliases(ctx, client, actions); err != nil {
+		return err
+	}
+	lp.FromContext(ctx).Info("moved alias", "alias", index.Alias, "index", target)
+	if legacy {
+		// remove_index deleted the legacy index together with its block
+		blocked = nil
+		return nil
+	}
+
+	if opts.DeleteOld {
+		if _, err := client.Indices.Delete(ctx, opensearchapi.IndicesDeleteReq{Indices: sources}); err != nil {
+			return fmt.Errorf("deleting old indices %v: %w", sources, err)
+		}
+		blocked = nil
+		lp.FromContext(ctx).Info("deleted old indices", "indices", sources)
+	}
+	return nil
+}
+
+// blockWrites sets or clears the write block of the indices
+func blockWrites(ctx context.Context, client *opensearchapi.Client, indices []string, block bool) error {
+	if len(indices) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(map[string]interface{}{"index.blocks.write": block})
+	if err != nil {
+		return err
+	}
+	if _, err := client.Indices.Settings.Put(ctx, opensearchapi.SettingsPutReq{
+		Indices: indices,
+		Body:    bytes.NewReader(data),
+	}); err != nil {
+		return fmt.Errorf("setting the write block of %v to %t: %w", indices, block, err)
+	}
+	lp.FromContext(ctx).Info("set write block", "indices", indices, "blocked", block)
+	return nil
+}
+
+// resolveAlias returns the indices behind the alias and whether a concrete index uses the alias name
+func resolveAlias(ctx context.Context, client *opensearchapi.Client, alias string) ([]string, bool, error) {
+	aliasResp, err := client.Indices.Alias.Get(ctx, opensearchapi.AliasGetReq{
+		Indices: []string{"_all"},
+		Alias:   []string{alias},
+	})
+	if err == nil {
+		names := make([]string, 0, len(aliasResp.Indices))
+		for name := range aliasResp.Indices {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, false, nil
+	}
+	if resp := aliasResp.Inspect().Response; resp == nil || resp.StatusCode != http.StatusNotFound {
+		return nil, false, fmt.Errorf("resolving alias %s: %w", alias, err)
+	}
+
+	existsResp, err := client.Indices.Exists(ctx, opensearchapi.IndicesExistsReq{Indices: []string{alias}})
+	if err == nil {
+		return nil, true, nil
+	}
+	if existsResp == nil || existsResp.StatusCode != http.StatusNotFound {
+		return nil, false, fmt.Errorf("checking index %s: %w", alias, err)
+	}
+	return nil, false, nil
+}
+
+// createIndex creates the versioned index. An index of that name left behind by an
+// interrupted run is deleted and created again when recreate is set, and kept otherwise.
+func createIndex(ctx context.Context, client *opensearchapi.Client, index Index, recreate bool) error {
+	existsResp, err := client.Indices.Exists(ctx, opensearchapi.IndicesExistsReq{Indices: []string{index.Name()}})
+	switch {
+	case err == nil && !recreate:
+		lp.FromContext(ctx).Info("index already exists", "index", index.Name())
+		return nil
+	case err == nil:
+		if _, err := client.Indices.Delete(ctx, opensearchapi.IndicesDeleteReq{Indices: []string{index.Name()}}); err != nil {
+			return fmt.Errorf("deleting index %s left by an interrupted migration: %w", index.Name(), err)
+		}
+		lp.FromContext(ctx).Info("deleted index left by an interrupted migration", "index", index.Name())
+	case existsResp == nil || existsResp.StatusCode != http.StatusNotFound:
+		return fmt.Errorf("checking index %s: %w", index.Name(), err)
+	}
+
+	body, err := index.Body()
+	if err != nil {
+		return err
+	}
+	if _, err := client.Indices.Create(ctx, opensearchapi.IndicesCreateReq{
+		Index: index.Name(),
+		Body:  bytes.NewReader(body),
+	}); err != nil {
+		return fmt.Errorf("creating index %s: %w", index.Name(), err)
+	}
+	lp.FromContext(ctx).Info("created index", "index", index.Name())
+	return nil
+}
+
+// reindex copies all documents from source to target, overwriting the ones already there.
+// The copy runs as an OpenSearch task that is polled until it completes, so no single
+// request has to outlive it and a lost connection does not start it twice. The task is
+// canceled when ctx is.
+func reindex(ctx context.Context, client *opensearchapi.Client, source, target string) error {
+	body := map[string]interface{}{
+		"source": map[string]interface{}{"index": source},
+		"dest":   map[string]interface{}{"index": target},
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Reindex(ctx, opensearchapi.ReindexReq{
+		Body: bytes.NewReader(data),
+		Params: opensearchapi.ReindexParams{
+			WaitForCompletion: opensearchapi.ToPointer(false),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("reindexing %s into %s: %w", source, target, err)
+	}
+	lp.FromContext(ctx).Info("started reindex", "source", source, "target", target, "task_id", resp.Task)
+
+	result, err := waitForReindex(ctx, client, resp.Task)
+	if err != nil {
+		return fmt.Errorf("reindexing %s into %s: %w", source, target, err)
+	}
+	if len(result.Failures) > 0 {
+		return fmt.Errorf("reindexing %s into %s: %d failures, first: %s", source, target, len(result.Failures), result.Failures[0])
+	}
+	if _, err := client.Indices.Refresh(ctx, &opensearchapi.IndicesRefreshReq{Indices: []string{target}}); err != nil {
+		return fmt.Errorf("refreshing %s: %w", target, err)
+	}
+	lp.FromContext(ctx).Info("reindexed documents", "source", source, "target", target, "created", result.Created, "updated", result.Updated)
+	return nil
+}
+
+// reindexPollInterval is the delay between two progress checks of a reindex task
+var reindexPollInterval = 2 * time.Second
+
+// reindexResult is the outcome of a completed reindex task
+type reindexResult struct {
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"`
+	Failures []json.RawMessage `json:"failures"`
+}
+
+// waitForReindex polls the reindex task until it completes and returns its result. The
+// typed tasks response drops the result, so the raw body is decoded.
+func waitForReindex(ctx context.Context, client *opensearchapi.Client, taskID string) (*reindexResult, error) {
+	defer func() {
+		if ctx.Err() == nil {
+			return
+		}
+		cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), callTimeout)
+		defer cancel()
+		if _, err := client.Tasks.Cancel(cancelCtx, opensearchapi.TasksCancelReq{TaskID: taskID}); err != nil {
+			lp.FromContext(ctx).Warn("failed to cancel the reindex task", "task_id", taskID, "error", err)
+		}
+	}()
+
+	ticker := time.NewTicker(reindexPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		raw, err := getReindexTask(ctx, client, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if !raw.Completed {
+			continue
+		}
+		if len(raw.Error) > 0 {
+			return nil, fmt.Errorf("task %s failed: %s", taskID, raw.Error)
+		}
+		if raw.Response == nil {
+			return nil, fmt.Errorf("task %s completed without a result", taskID)
+		}
+		return raw.Response, nil
+	}
+}
+
+// reindexTask is the raw body of the tasks API for a reindex task
+type reindexTask struct {
+	Completed bool            `json:"completed"`
+	Response  *reindexResult  `json:"response"`
+	Error     json.RawMessage `json:"error"`
+}
+
+// getReindexTask checks a reindex task once
+func getReindexTask(ctx context.Context, client *opensearchapi.Client, taskID string) (*reindexTask, error) {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+	res, err := client.Tasks.Get(ctx, opensearchapi.TasksGetReq{TaskID: taskID})
+	if err != nil {
+		return nil, fmt.Errorf("getting task %s: %w", taskID, err)
+	}
+	var raw reindexTask
+	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+	return &raw, nil
+}
+
+func updateAliases(ctx context.Context, client *opensearchapi.Client, actions []map[string]interface{}) error {
+	data, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return err
+	}
+	if _, err := client.Aliases(ctx, opensearchapi.AliasesReq{Body: bytes.NewReader(data)}); err != nil {
+		return fmt.Errorf("updating aliases: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+// newTaskServer answers the tasks API with the given bodies in turn, repeating the last
+// one, and counts the cancel requests
+func newTaskServer(t *testing.T, bodies ...string) (*opensearchapi.Client, *atomic.Int32) {
+	t.Helper()
+	var polls, cancels atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/_tasks/node:1/_cancel" {
+			cancels.Add(1)
+			w.Write([]byte(`{"nodes":{}}`))
+			return
+		}
+		n := int(polls.Add(1))
+		w.Write([]byte(bodies[min(n, len(bodies))-1]))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := opensearchapi.NewClient(opensearchapi.Config{Client: opensearch.Config{Addresses: []string{server.URL}, DisableRetry: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &cancels
+}
+
+func TestWaitForReindex(t *testing.T) {
+	reindexPollInterval = time.Millisecond
+	const running = `{"completed":false,"task":{"status":{"total":2,"created":1}}}`
+
+	tests := []struct {
+		name        string
+		bodies      []string
+		wantCreated int
+		wantErr     bool
+	}{
+		{name: "completed after polls", bodies: []string{running, running, `{"completed":true,"response":{"created":2,"updated":0,"failures":[]}}`}, wantCreated: 2},
+		{name: "failures are returned", bodies: []string{`{"completed":true,"response":{"created":1,"failures":[{"id":"v2"}]}}`}, wantCreated: 1},
+		{name: "task error", bodies: []string{`{"completed":true,"error":{"type":"index_not_found_exception"}}`}, wantErr: true},
+		{name: "completed without result", bodies: []string{`{"completed":true}`}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTaskServer(t, tt.bodies...)
+			result, err := waitForReindex(context.Background(), client, "node:1")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("waitForReindex = %+v, want an error", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Created != tt.wantCreated {
+				t.Errorf("created = %d, want %d", result.Created, tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestWaitForReindexCancelsTask(t *testing.T) {
+	reindexPollInterval = time.Millisecond
+	client, cancels := newTaskServer(t, `{"completed":false}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := waitForReindex(ctx, client, "node:1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("waitForReindex error = %v, want the context error", err)
+	}
+	if cancels.Load() != 1 {
+		t.Errorf("task canceled %d times, want once", cancels.Load())
+	}
+}
@@ -0,0 +1,54 @@
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+//go:embed mappings/*.json
+var mappings embed.FS
+
+// Index describes a versioned index. Readers and writers always go through Alias, which
+// points at the concrete index <alias>_v<version>. Bump Version whenever the mapping or
+// settings file changes so that Migrate builds a new index and moves the alias to it.
+type Index struct {
+	Alias   string
+	Version int
+	File    string
+}
+
+var (
+	Videos     = Index{Alias: "videos", Version: 4, File: "videos.json"}
+	Creators   = Index{Alias: "creators", Version: 1, File: "creators.json"}
+	Categories = Index{Alias: "categories", Version: 2, File: "categories.json"}
+	Banners    = Index{Alias: "banners", Version: 2, File: "banners.json"}
+)
+
+// All returns every index managed by the migrations
+func All() []Index {
+	return []Index{Videos, Creators, Categories, Banners}
+}
+
+// Name returns the concrete index name for the current version
+func (i Index) Name() string {
+	return fmt.Sprintf("%s_v%d", i.Alias, i.Version)
+}
+
+// Body returns the settings and mappings used to create the index
+func (i Index) Body() ([]byte, error) {
+	return mappings.ReadFile("mappings/" + i.File)
+}
+
+var versionSuffix = regexp.MustCompile(`_v(\d+)$`)
+
+// versionOf extracts the version from a concrete index name, or 0 when the name is unversioned
+func versionOf(name string) int {
+	match := versionSuffix.FindStringSubmatch(name)
+	if match == nil {
+		return 0
+	}
+	version, _ := strconv.Atoi(match[1])
+	return version
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIndexBodies(t *testing.T) {
+	for _, index := range All() {
+		t.Run(index.Alias, func(t *testing.T) {
+			body, err := index.Body()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var definition map[string]interface{}
+			if err := json.Unmarshal(body, &definition); err != nil {
+				t.Fatalf("%s is not valid JSON: %s", index.File, err)
+			}
+			if _, ok := definition["mappings"]; !ok {
+				t.Errorf("%s has no mappings", index.File)
+			}
+		})
+	}
+}
+
+func TestVideosSynonymsPath(t *testing.T) {
+	body, err := Videos.Body()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var definition struct {
+		Settings struct {
+			Analysis struct {
+				Filter map[string]struct {
+					SynonymsPath string `json:"synonyms_path"`
+				} `json:"filter"`
+			} `json:"analysis"`
+		} `json:"settings"`
+	}
+	if err := json.Unmarshal(body, &definition); err != nil {
+		t.Fatal(err)
+	}
+	if path := definition.Settings.Analysis.Filter["islamic_synonyms"].SynonymsPath; path != SynonymsPath {
+		t.Errorf("videos.json reads synonyms from %q, CheckSynonyms checks %q", path, SynonymsPath)
+	}
+}
+
+func TestVersionOf(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"videos_v4", 4},
+		{"videos_v12", 12},
+		{"videos", 0},
+		{"videos_vx", 0},
+		{"my_videos_v2", 2},
+	}
+	for _, tt := range tests {
+		if got := versionOf(tt.name); got != tt.want {
+			t.Errorf("versionOf(%q) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MemoryVideoRepository keeps videos in memory. It mirrors the matching, ordering and
+// pagination rules of the OpenSearch queries so handlers can be exercised without a cluster.
+type MemoryVideoRepository struct {
+	mu     sync.RWMutex
+	videos map[string]*models.Video
+	// order records insertion order so equally scored hits come back in a stable order
+	order []string
+	// tasks holds the finished category updates, the task ID is the position plus one
+	tasks []*models.CategoryTask
+}
+
+// NewMemoryVideoRepository returns an empty in-memory VideoRepository
+func NewMemoryVideoRepository() *MemoryVideoRepository {
+	return &MemoryVideoRepository{videos: map[string]*models.Video{}}
+}
+
+func (r *MemoryVideoRepository) GetVideoByID(ctx context.Context, videoID string) (*models.Video, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	video, ok := r.videos[videoID]
+	if !ok {
+		return nil, errs.NotFound("video", videoID)
+	}
+	return copyVideo(video), nil
+}
+
+func (r *MemoryVideoRepository) GetVideosByIDs(ctx context.Context, videoIDs []string) (map[string]*models.Video, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	videos := make(map[string]*models.Video, len(videoIDs))
+	for _, videoID := range videoIDs {
+		if video, ok := r.videos[videoID]; ok {
+			videos[videoID] = copyVideo(video)
+		}
+	}
+	return videos, nil
+}
+
+func (r *MemoryVideoRepository) InsertVideo(ctx context.Context, video *models.Video) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.put(video)
+	return nil
+}
+
+// UpdateVideo replaces the stored document, creating it when missing like an index request does
+func (r *MemoryVideoRepository) UpdateVideo(ctx context.Context, video *models.Video) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.put(video)
+	return nil
+}
+
+func (r *MemoryVideoRepository) UpdateVideoMetadata(ctx context.Context, video *models.Video) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.videos[video.VideoID]
+	if !ok {
+		return errs.NotFound("video", video.VideoID)
+	}
+	updated := copyVideo(video)
+	updated.Categories = stored.Categories
+	r.put(updated)
+	return nil
+}
+
+func (r *MemoryVideoRepository) DeleteVideoByID(ctx context.Context, videoID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.videos[videoID]; !ok {
+		return errs.NotFound("video", videoID)
+	}
+	delete(r.videos, videoID)
+	for i, id := range r.order {
+		if id == videoID {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// BulkIndexVideos stores every video, reporting "created" or "updated" like the bulk API
+func (r *MemoryVideoRepository) BulkIndexVideos(ctx context.Context, videos []*models.Video) ([]models.BulkItemResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]models.BulkItemResult, len(videos))
+	for i, video := range videos {
+		results[i] = models.BulkItemResult{ID: video.VideoID, Result: "created"}
+		if _, ok := r.videos[video.VideoID]; ok {
+			results[i].Result = "updated"
+		}
+		r.put(video)
+	}
+	return results, nil
+}
+
+// SearchVideosByCategory matches the categories exactly like the term queries on
+// categories.keyword and orders the videos by the number of matching categories, then by
+// upload date and video ID
+func (r *MemoryVideoRepository) SearchVideosByCategory(ctx context.Context, categories []string, page models.Page) (*models.VideoPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var hits []scoredVideo
+	for _, id := range r.order {
+		video := r.videos[id]
+		matched := 0
+		for _, category := range categories {
+			if slices.Contains(video.Categories, category) {
+				matched++
+			}
+		}
+		if matched > 0 {
+			hits = append(hits, scoredVideo{video: video, score: float64(matched)})
+		}
+	}
+	sortHits(hits, models.SortRelevance)
+
+	selected, next, err := pageOf(hits, models.SortRelevance, page)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.VideoPage{Total: len(hits), Videos: make([]*models.Video, len(selected)), Next: next}
+	for i, hit := range selected {
+		result.Videos[i] = copyVideo(hit.video)
+	}
+	return result, nil
+}
+
+// CountVideosByCategory counts the videos in each of the given categories, matching the
+// slugs exactly like the terms aggregation on categories.keyword
+func (r *MemoryVideoRepository) CountVideosByCategory(ctx context.Context, slugs []string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int, len(slugs))
+	wanted := map[string]bool{}
+	for _, slug := range slugs {
+		wanted[slug] = true
+	}
+	for _, video := range r.videos {
+		for _, category := range distinct(video.Categories) {
+			if wanted[category] {
+				counts[category]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// CountVideosInSubtrees counts for each key the videos with any of its category slugs
+func (r *MemoryVideoRepository) CountVideosInSubtrees(ctx context.Context, subtrees map[string][]string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int, len(subtrees))
+	for key, slugs := range subtrees {
+		wanted := map[string]bool{}
+		for _, slug := range slugs {
+			wanted[slug] = true
+		}
+		for _, video := range r.videos {
+			for _, category := range video.Categories {
+				if wanted[category] {
+					counts[key]++
+					break
+				}
+			}
+		}
+	}
+	return counts, nil
+}
+
+// GetVideoStats counts all videos and the videos per category
+func (r *MemoryVideoRepository) GetVideoStats(ctx context.Context) (*models.VideoStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return &models.VideoStats{Total: len(r.videos), Categories: r.categoryCounts()}, nil
+}
+
+// categoryCounts returns up to 1000 categories ordered by video count, then name.
+// The caller must hold the lock.
+func (r *MemoryVideoRepository) categoryCounts() []models.CategoryCount {
+	counts := map[string]int{}
+	for _, video := range r.videos {
+		for _, category := range distinct(video.Categories) {
+			counts[category]++
+		}
+	}
+	return topCategories(counts, 1000)
+}
+
+// GetStaleVideos returns never refreshed videos first, then the least recently refreshed ones
+func (r *MemoryVideoRepository) GetStaleVideos(ctx context.Context, olderThan time.Time, limit int) ([]*models.Video, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cutoff := olderThan.UTC().Format(time.RFC3339)
+	var stale []*models.Video
+	for _, id := range r.order {
+		video := r.videos[id]
+		if video.RefreshedAt == "" || video.RefreshedAt < cutoff {
+			stale = append(stale, copyVideo(video))
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool { return stale[i].RefreshedAt < stale[j].RefreshedAt })
+	return paginate(stale, 0, limit), nil
+}
+
+// videosByCreator returns the creator's videos newest first
+func (r *MemoryVideoRepository) videosByCreator(creator *models.Creator) []*models.Video {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var videos []*models.Video
+	for _, id := range r.order {
+		video := r.videos[id]
+		if video.CreatorDetails.CreatorID == creator.CreatorID ||
+			(creator.ChannelLink != "" && video.CreatorDetails.ChannelLink == creator.ChannelLink) {
+			videos = append(videos, video)
+		}
+	}
+	sort.SliceStable(videos, func(i, j int) bool { return videos[i].UploadDate > videos[j].UploadDate })
+	return videos
+}
+
+func (r *MemoryVideoRepository) put(video *models.Video) {
+	if _, ok := r.videos[video.VideoID]; !ok {
+		r.order = append(r.order, video.VideoID)
+	}
+	r.videos[video.VideoID] = copyVideo(video)
+}
+
+// MemoryCreatorRepository keeps creators in memory and looks up their videos in a MemoryVideoRepository
+type MemoryCreatorRepository struct {
+	mu       sync.RWMutex
+	creators map[string]*models.Creator
+	videos   *MemoryVideoRepository
+}
+
+// NewMemoryCreatorRepository returns an empty in-memory CreatorRepository
+func NewMemoryCreatorRepository(videos *MemoryVideoRepository) *MemoryCreatorRepository {
+	return &MemoryCreatorRepository{creators: map[string]*models.Creator{}, videos: videos}
+}
+
+func (r *MemoryCreatorRepository) InsertOrUpdateCreator(ctx context.Context, creator *models.Creator) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *creator
+	r.creators[creator.CreatorID] = &stored
+	return nil
+}
+
+func (r *MemoryCreatorRepository) GetCreatorByID(ctx context.Context, creatorID string) (*models.Creator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	creator, ok := r.creators[creatorID]
+	if !ok {
+		return nil, errs.NotFound("creator", creatorID)
+	}
+	found := *creator
+	return &found, nil
+}
+
+// GetCreators lists creators ordered by name
+func (r *MemoryCreatorRepository) GetCreators(ctx context.Context, from int, size int) (int, []*models.Creator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	creators := make([]*models.Creator, 0, len(r.creators))
+	for _, creator := range r.creators {
+		found := *creator
+		creators = append(creators, &found)
+	}
+	sort.Slice(creators, func(i, j int) bool {
+		if creators[i].Name != creators[j].Name {
+			return creators[i].Name < creators[j].Name
+		}
+		return creators[i].CreatorID < creators[j].CreatorID
+	})
+
+	total := len(creators)
+	if from >= total {
+		return total, []*models.Creator{}, nil
+	}
+	end := from + size
+	if end > total {
+		end = total
+	}
+	return total, creators[from:end], nil
+}
+
+func (r *MemoryCreatorRepository) SearchVideosByCreator(ctx context.Context, creator *models.Creator, from int, size int) (int, []*models.Video, error) {
+	videos := r.videos.videosByCreator(creator)
+	return len(videos), paginate(videos, from, size), nil
+}
+
+// paginate returns copies of the videos in the [from, from+size) window
+func paginate(videos []*models.Video, from int, size int) []*models.Video {
+	if from < 0 {
+		from = 0
+	}
+	if from >= len(videos) {
+		return []*models.Video{}
+	}
+	end := from + size
+	if end > len(videos) {
+		end = len(videos)
+	}
+
+	page := make([]*models.Video, 0, end-from)
+	for _, video := range videos[from:end] {
+		page = append(page, copyVideo(video))
+	}
+	return page
+}
+
+// analyze splits the text into letter/digit tokens like the standard analyzer and folds
+// them like the multilingual analyzer
+func analyze(text string) []string {
+	tokens := strings.FieldsFunc(text, func(r rune) bool {
+		return !inToken(r)
+	})
+	for i, token := range tokens {
+		tokens[i] = fold(token)
+	}
+	return tokens
+}
+
+// inToken reports whether r belongs to a token. Combining marks such as Arabic
+// diacritics stay attached to their letter.
+func inToken(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r)
+}
+
+// letterVariants maps the Arabic and Urdu letter variants to the letter the
+// arabic_normalization and persian_normalization filters index them as
+var letterVariants = map[rune]rune{
+	'\u0649': '\u064A', // alef maksura to yeh
+	'\u06CC': '\u064A', // farsi yeh
+	'\u06D2': '\u064A', // yeh barree
+	'\u0629': '\u0647', // teh marbuta to heh
+	'\u06C3': '\u0647', // teh marbuta goal
+	'\u06C1': '\u0647', // heh goal
+	'\u06BE': '\u0647', // heh doachashmee
+	'\u06A9': '\u0643', // keheh to kaf
+}
+
+// fold lowercases a token, drops diacritics and tatweel, and normalizes letter variants
+// and Arabic-Indic digits
+func fold(token string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(token)) {
+		switch {
+		case unicode.Is(unicode.Mn, r) || r == '\u0640':
+			continue
+		case r >= '\u0660' && r <= '\u0669':
+			r = '0' + r - '\u0660'
+		case r >= '\u06F0' && r <= '\u06F9':
+			r = '0' + r - '\u06F0'
+		}
+		if variant, ok := letterVariants[r]; ok {
+			r = variant
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func copyVideo(video *models.Video) *models.Video {
+	copied := *video
+	copied.Thumbnails = append([]models.Thumbnail(nil), video.Thumbnails...)
+	copied.Categories = append([]string(nil), video.Categories...)
+	if video.Likes != nil {
+		likes := *video.Likes
+		copied.Likes = &likes
+	}
+	if video.Dislikes != nil {
+		dislikes := *video.Dislikes
+		copied.Dislikes = &dislikes
+	}
+	return &copied
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// MemoryBannerRepository keeps banners in memory
+type MemoryBannerRepository struct {
+	mu      sync.RWMutex
+	banners map[string]*models.Banner
+}
+
+// NewMemoryBannerRepository returns an empty in-memory BannerRepository
+func NewMemoryBannerRepository() *MemoryBannerRepository {
+	return &MemoryBannerRepository{banners: map[string]*models.Banner{}}
+}
+
+// GetBanners lists the banners ordered by position, then ID
+func (r *MemoryBannerRepository) GetBanners(ctx context.Context) ([]*models.Banner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	banners := make([]*models.Banner, 0, len(r.banners))
+	for _, banner := range r.banners {
+		banners = append(banners, copyBanner(banner))
+	}
+	sort.Slice(banners, func(i, j int) bool {
+		if banners[i].Position != banners[j].Position {
+			return banners[i].Position < banners[j].Position
+		}
+		return banners[i].ID < banners[j].ID
+	})
+	return banners, nil
+}
+
+// GetActiveBanners lists the banners active at now for locale in the order of GetBanners
+func (r *MemoryBannerRepository) GetActiveBanners(ctx context.Context, now time.Time, locale string) ([]*models.Banner, error) {
+	banners, err := r.GetBanners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active := []*models.Banner{}
+	for _, banner := range banners {
+		if banner.ActiveAt(now, locale) {
+			active = append(active, banner)
+		}
+	}
+	return active, nil
+}
+
+func (r *MemoryBannerRepository) GetBannerByID(ctx context.Context, id string) (*models.Banner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	banner, ok := r.banners[id]
+	if !ok {
+		return nil, errs.NotFound("banner", id)
+	}
+	return copyBanner(banner), nil
+}
+
+func (r *MemoryBannerRepository) CreateBanner(ctx context.Context, banner *models.Banner) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.banners[banner.ID]; ok {
+		return errs.Conflict("banner %s already exists", banner.ID)
+	}
+	r.banners[banner.ID] = copyBanner(banner)
+	return nil
+}
+
+func (r *MemoryBannerRepository) UpdateBanner(ctx context.Context, banner *models.Banner) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.banners[banner.ID]; !ok {
+		return errs.NotFound("banner", banner.ID)
+	}
+	r.banners[banner.ID] = copyBanner(banner)
+	return nil
+}
+
+func (r *MemoryBannerRepository) DeleteBanner(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.banners[id]; !ok {
+		return errs.NotFound("banner", id)
+	}
+	delete(r.banners, id)
+	return nil
+}
+
+func copyBanner(banner *models.Banner) *models.Banner {
+	copied := *banner
+	copied.Locales = append([]string(nil), banner.Locales...)
+	return &copied
+}
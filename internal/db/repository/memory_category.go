@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// MemoryCategoryRepository keeps categories in memory
+type MemoryCategoryRepository struct {
+	mu         sync.RWMutex
+	categories map[string]*models.Category
+}
+
+// NewMemoryCategoryRepository returns an empty in-memory CategoryRepository
+func NewMemoryCategoryRepository() *MemoryCategoryRepository {
+	return &MemoryCategoryRepository{categories: map[string]*models.Category{}}
+}
+
+// GetCategories lists the categories ordered by sort order, then slug
+func (r *MemoryCategoryRepository) GetCategories(ctx context.Context) ([]*models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := make([]*models.Category, 0, len(r.categories))
+	for _, category := range r.categories {
+		categories = append(categories, copyCategory(category))
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		return categories[i].Slug < categories[j].Slug
+	})
+	return categories, nil
+}
+
+func (r *MemoryCategoryRepository) GetCategoryBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	category, ok := r.categories[slug]
+	if !ok {
+		return nil, errs.NotFound("category", slug)
+	}
+	return copyCategory(category), nil
+}
+
+func (r *MemoryCategoryRepository) CreateCategory(ctx context.Context, category *models.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[category.Slug]; ok {
+		return errs.Conflict("category %s already exists", category.Slug)
+	}
+	r.categories[category.Slug] = copyCategory(category)
+	return nil
+}
+
+func (r *MemoryCategoryRepository) UpdateCategory(ctx context.Context, category *models.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[category.Slug]; !ok {
+		return errs.NotFound("category", category.Slug)
+	}
+	r.categories[category.Slug] = copyCategory(category)
+	return nil
+}
+
+func (r *MemoryCategoryRepository) DeleteCategory(ctx context.Context, slug string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[slug]; !ok {
+		return errs.NotFound("category", slug)
+	}
+	delete(r.categories, slug)
+	return nil
+}
+
+func copyCategory(category *models.Category) *models.Category {
+	copied := *category
+	copied.Names = make(map[string]string, len(category.Names))
+	for lang, name := range category.Names {
+		copied.Names[lang] = name
+	}
+	return &copied
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// pageOf returns the page of the sorted hits and the cursor of the next page, nil when no
+// hits follow. A cursor page starts at the first hit sorting after the cursor position,
+// like search_after. Points in time are not kept, cursors read the live data.
+func pageOf(hits []scoredVideo, order string, page models.Page) ([]scoredVideo, *models.Cursor, error) {
+	start := page.From
+	if page.Cursor != nil {
+		last, err := cursorHit(page.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		start = sort.Search(len(hits), func(i int) bool {
+			return sortsBefore(last, hits[i], order)
+		})
+	}
+	start = min(max(start, 0), len(hits))
+	end := min(start+page.Size, len(hits))
+
+	var next *models.Cursor
+	if end > start && end < len(hits) {
+		next = &models.Cursor{After: cursorValues(hits[end-1]), Sort: order}
+	}
+	return hits[start:end], next, nil
+}
+
+// cursorValues returns the values of every sort key of a hit: score, views, likes,
+// upload time and video ID. Missing values are nil.
+func cursorValues(hit scoredVideo) []interface{} {
+	optional := func(value int64, ok bool) interface{} {
+		if !ok {
+			return nil
+		}
+		return value
+	}
+	return []interface{}{
+		hit.score,
+		optional(viewsOf(hit.video)),
+		optional(likesOf(hit.video)),
+		optional(uploadedAt(hit.video)),
+		hit.video.VideoID,
+	}
+}
+
+// cursorHit rebuilds the sort keys of the hit a cursor was created after
+func cursorHit(cursor *models.Cursor) (scoredVideo, error) {
+	invalid := errs.Validation("cursor is invalid")
+	if len(cursor.After) != 5 {
+		return scoredVideo{}, invalid
+	}
+	score, err := strconv.ParseFloat(fmt.Sprint(cursor.After[0]), 64)
+	if err != nil {
+		return scoredVideo{}, invalid
+	}
+	views, hasViews, err1 := optionalInt(cursor.After[1])
+	likes, hasLikes, err2 := optionalInt(cursor.After[2])
+	uploaded, hasUpload, err3 := optionalInt(cursor.After[3])
+	videoID, ok := cursor.After[4].(string)
+	if err1 != nil || err2 != nil || err3 != nil || !ok {
+		return scoredVideo{}, invalid
+	}
+
+	video := &models.Video{VideoID: videoID}
+	if hasViews {
+		video.ViewsCount = strconv.FormatInt(views, 10)
+	}
+	if hasLikes {
+		count := int(likes)
+		video.Likes = &count
+	}
+	if hasUpload {
+		video.UploadDate = time.Unix(0, uploaded).UTC().Format(time.RFC3339Nano)
+	}
+	return scoredVideo{video: video, score: score}, nil
+}
+
+// optionalInt parses a cursor value written by cursorValues, reporting whether it was set
+func optionalInt(value interface{}) (int64, bool, error) {
+	if value == nil {
+		return 0, false, nil
+	}
+	number, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	return number, true, err
+}
@@ -0,0 +1,453 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+const (
+	// facetSize is the number of values returned per facet, as in the OpenSearch query
+	facetSize = 20
+	// fragmentSize and fragmentCount bound the description highlights
+	fragmentSize  = 150
+	fragmentCount = 3
+)
+
+// scoredVideo is a search hit with its relevance score
+type scoredVideo struct {
+	video *models.Video
+	score float64
+}
+
+// SearchVideos matches any query term against title, description and categories and ranks
+// videos by the best matching field, like a best_fields multi_match query. Terms within
+// the allowed edit distance count half, and titles containing the query as a phrase get
+// the phrase boost on top. Diacritics and Arabic and Urdu letter variants are folded like
+// the multilingual subfields, but the synonyms file is not applied. An empty query
+// matches every video. The category and creator facets each ignore their own filter,
+// like the filter aggregations next to the post filter of the OpenSearch query.
+func (r *MemoryVideoRepository) SearchVideos(ctx context.Context, search models.VideoSearch) (*models.VideoSearchResult, error) {
+	terms := analyze(search.Query)
+	filters := search.Filters
+	from, until, err := filters.UploadBounds()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var hits []scoredVideo
+	categories := map[string]int{}
+	creators := map[string]*models.CreatorCount{}
+	for _, id := range r.order {
+		video := r.videos[id]
+		score := 0.0
+		for _, field := range []string{video.Title, video.Description, strings.Join(video.Categories, " ")} {
+			score = max(score, fieldScore(terms, analyze(field), search.Match))
+		}
+		if score > 0 && search.Match.PhraseBoost > 0 && containsPhrase(analyze(video.Title), terms) {
+			score += search.Match.PhraseBoost
+		}
+		if len(terms) > 0 && score == 0 || !matchesBaseFilters(video, filters, from, until) {
+			continue
+		}
+
+		inCategories := matchesCategories(video, filters)
+		ofCreator := filters.CreatorID == "" || video.CreatorDetails.CreatorID == filters.CreatorID
+		if ofCreator {
+			for _, category := range distinct(video.Categories) {
+				categories[category]++
+			}
+		}
+		if inCategories && video.CreatorDetails.CreatorID != "" {
+			count, ok := creators[video.CreatorDetails.CreatorID]
+			if !ok {
+				count = &models.CreatorCount{CreatorID: video.CreatorDetails.CreatorID, Name: video.CreatorDetails.Name}
+				creators[count.CreatorID] = count
+			}
+			count.Count++
+		}
+		if inCategories && ofCreator {
+			hits = append(hits, scoredVideo{video: video, score: score})
+		}
+	}
+	sortHits(hits, search.Sort)
+
+	page, next, err := pageOf(hits, search.Sort, search.Page)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*models.VideoHit, len(page))
+	for i, hit := range page {
+		video := copyVideo(hit.video)
+		results[i] = &models.VideoHit{Video: video, Highlights: highlights(video, terms, search.Match)}
+	}
+	return &models.VideoSearchResult{
+		Total: len(hits),
+		Hits:  results,
+		Facets: models.SearchFacets{
+			Categories: topCategories(categories, facetSize),
+			Creators:   topCreators(creators, facetSize),
+		},
+		Next: next,
+	}, nil
+}
+
+// matchesBaseFilters applies the filters shared by the hits and the facets. Malformed
+// upload dates and view counts never match a bound, like ignore_malformed fields.
+func matchesBaseFilters(video *models.Video, filters models.SearchFilters, from, until time.Time) bool {
+	if filters.IsShort != nil && video.IsShort != *filters.IsShort {
+		return false
+	}
+	if !from.IsZero() || !until.IsZero() {
+		uploaded, err := models.ParseDate(video.UploadDate)
+		if err != nil || (!from.IsZero() && uploaded.Before(from)) || (!until.IsZero() && !uploaded.Before(until)) {
+			return false
+		}
+	}
+	if filters.MinViews > 0 {
+		views, ok := viewsOf(video)
+		if !ok || views < int64(filters.MinViews) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesCategories applies the exact categories filters
+func matchesCategories(video *models.Video, filters models.SearchFilters) bool {
+	has := map[string]bool{}
+	for _, category := range video.Categories {
+		has[category] = true
+	}
+	if len(filters.CategoriesAny) > 0 {
+		found := false
+		for _, category := range filters.CategoriesAny {
+			found = found || has[category]
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, category := range filters.CategoriesAll {
+		if !has[category] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortHits orders the hits like the sort clauses of the OpenSearch query: the primary
+// key of the order, then the newest upload, then the video ID
+func sortHits(hits []scoredVideo, order string) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		return sortsBefore(hits[i], hits[j], order)
+	})
+}
+
+// sortsBefore reports whether hit x comes before hit y in the order
+func sortsBefore(x, y scoredVideo, order string) bool {
+	a, b := x.video, y.video
+	switch order {
+	case models.SortNewest:
+	case models.SortMostViewed:
+		if c := key(viewsOf(a)).compare(key(viewsOf(b))); c != 0 {
+			return c < 0
+		}
+	case models.SortMostLiked:
+		if c := key(likesOf(a)).compare(key(likesOf(b))); c != 0 {
+			return c < 0
+		}
+	default:
+		if x.score != y.score {
+			return x.score > y.score
+		}
+	}
+	if c := key(uploadedAt(a)).compare(key(uploadedAt(b))); c != 0 {
+		return c < 0
+	}
+	return a.VideoID < b.VideoID
+}
+
+// sortKey is an optional numeric sort value
+type sortKey struct {
+	value int64
+	ok    bool
+}
+
+func key(value int64, ok bool) sortKey {
+	return sortKey{value: value, ok: ok}
+}
+
+// compare orders descending with missing values last. It returns a negative number
+// when k sorts first and zero on a tie.
+func (k sortKey) compare(other sortKey) int {
+	switch {
+	case k.ok != other.ok:
+		if k.ok {
+			return -1
+		}
+		return 1
+	case !k.ok || k.value == other.value:
+		return 0
+	case k.value > other.value:
+		return -1
+	}
+	return 1
+}
+
+// viewsOf parses the view count the way OpenSearch coerces it into a long
+func viewsOf(video *models.Video) (int64, bool) {
+	views, err := strconv.ParseInt(strings.TrimSpace(video.ViewsCount), 10, 64)
+	return views, err == nil
+}
+
+func likesOf(video *models.Video) (int64, bool) {
+	if video.Likes == nil {
+		return 0, false
+	}
+	return int64(*video.Likes), true
+}
+
+func uploadedAt(video *models.Video) (int64, bool) {
+	uploaded, err := models.ParseDate(video.UploadDate)
+	return uploaded.UnixNano(), err == nil
+}
+
+// topCategories returns the size largest counts, ties ordered by name like a terms aggregation
+func topCategories(counts map[string]int, size int) []models.CategoryCount {
+	result := make([]models.CategoryCount, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, models.CategoryCount{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result[:min(size, len(result))]
+}
+
+// topCreators returns the size largest counts, ties ordered by creator ID
+func topCreators(counts map[string]*models.CreatorCount, size int) []models.CreatorCount {
+	result := make([]models.CreatorCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].CreatorID < result[j].CreatorID
+	})
+	return result[:min(size, len(result))]
+}
+
+// distinct returns the values without repetitions, keeping their order
+func distinct(values []string) []string {
+	seen := map[string]bool{}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// fieldScore adds up the best match of every distinct query term in the field tokens
+func fieldScore(terms []string, tokens []string, match models.TextMatch) float64 {
+	score := 0.0
+	seen := map[string]bool{}
+	for _, term := range terms {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		best := 0.0
+		for _, token := range tokens {
+			best = max(best, termScore(term, token, match))
+		}
+		score += best
+	}
+	return score
+}
+
+// termScore is 1 for an exact match, 0.5 for a match within the allowed edit distance
+// and 0 otherwise
+func termScore(term, token string, match models.TextMatch) float64 {
+	if term == token {
+		return 1
+	}
+	edits := maxEdits(term, match.Fuzziness)
+	if edits == 0 {
+		return 0
+	}
+	termRunes, tokenRunes := []rune(term), []rune(token)
+	prefix := match.PrefixLength
+	if prefix > len(termRunes) || prefix > len(tokenRunes) || string(termRunes[:prefix]) != string(tokenRunes[:prefix]) {
+		return 0
+	}
+	if editDistance(termRunes, tokenRunes) <= edits {
+		return 0.5
+	}
+	return 0
+}
+
+// maxEdits returns the edit distance fuzziness allows for term. AUTO allows none below
+// 3 characters, one below 6 and two from there on.
+func maxEdits(term string, fuzziness string) int {
+	switch strings.ToUpper(fuzziness) {
+	case "1":
+		return 1
+	case "2":
+		return 2
+	case "AUTO":
+		switch length := utf8.RuneCountInString(term); {
+		case length < 3:
+			return 0
+		case length < 6:
+			return 1
+		}
+		return 2
+	}
+	return 0
+}
+
+// editDistance counts the insertions, deletions, substitutions and adjacent
+// transpositions turning a into b
+func editDistance(a, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+// containsPhrase reports whether the terms appear in order and next to each other
+func containsPhrase(tokens []string, terms []string) bool {
+	if len(terms) == 0 {
+		return false
+	}
+	for start := 0; start+len(terms) <= len(tokens); start++ {
+		matched := true
+		for i, term := range terms {
+			if tokens[start+i] != term {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// highlights returns the whole title and up to fragmentCount description fragments of
+// about fragmentSize bytes that contain a matching term, like the highlight section of
+// the OpenSearch query
+func highlights(video *models.Video, terms []string, match models.TextMatch) map[string][]string {
+	if len(terms) == 0 {
+		return nil
+	}
+	result := map[string][]string{}
+	if title, ok := highlight(video.Title, terms, match); ok {
+		result["title"] = []string{title}
+	}
+
+	spans := tokenSpans(video.Description)
+	start := 0
+	for i := 0; i < len(spans) && len(result["description"]) < fragmentCount; {
+		// Extend the fragment by whole tokens up to fragmentSize bytes
+		end := i
+		for end < len(spans) && (end == i || spans[end][1]-start <= fragmentSize) {
+			end++
+		}
+		stop := len(video.Description)
+		if end < len(spans) {
+			stop = spans[end][0]
+		}
+		if fragment, ok := highlight(video.Description[start:stop], terms, match); ok {
+			result["description"] = append(result["description"], strings.TrimSpace(fragment))
+		}
+		i, start = end, stop
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// highlight HTML escapes text and wraps the tokens matching a term in <em> tags. It
+// reports whether any token matched.
+func highlight(text string, terms []string, match models.TextMatch) (string, bool) {
+	var b strings.Builder
+	matched := false
+	last := 0
+	for _, span := range tokenSpans(text) {
+		token := fold(text[span[0]:span[1]])
+		hit := false
+		for _, term := range terms {
+			if termScore(term, token, match) > 0 {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			continue
+		}
+		matched = true
+		b.WriteString(html.EscapeString(text[last:span[0]]))
+		b.WriteString("<em>" + html.EscapeString(text[span[0]:span[1]]) + "</em>")
+		last = span[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String(), matched
+}
+
+// tokenSpans returns the byte offsets of the tokens analyze would produce from text
+func tokenSpans(text string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range text {
+		switch {
+		case inToken(r) && start < 0:
+			start = i
+		case !inToken(r) && start >= 0:
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(text)})
+	}
+	return spans
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// Suggest completes the query to titles, creators and categories, matching the last word
+// as a prefix like the bool_prefix queries. Titles are ordered by views and deduplicated
+// ignoring case; creators and categories by their number of videos.
+func (r *MemoryVideoRepository) Suggest(ctx context.Context, query string, size int) (*models.Suggestions, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var titles []*models.Video
+	creators := map[string]*models.CreatorCount{}
+	categories := map[string]int{}
+	for _, id := range r.order {
+		video := r.videos[id]
+		if models.MatchesPrefix(video.Title, query) {
+			titles = append(titles, video)
+		}
+		if video.CreatorDetails.CreatorID != "" && models.MatchesPrefix(video.CreatorDetails.Name, query) {
+			count, ok := creators[video.CreatorDetails.CreatorID]
+			if !ok {
+				count = &models.CreatorCount{CreatorID: video.CreatorDetails.CreatorID, Name: video.CreatorDetails.Name}
+				creators[count.CreatorID] = count
+			}
+			count.Count++
+		}
+		for _, category := range distinct(video.Categories) {
+			if models.MatchesPrefix(category, query) {
+				categories[category]++
+			}
+		}
+	}
+	sort.SliceStable(titles, func(i, j int) bool {
+		if c := key(viewsOf(titles[i])).compare(key(viewsOf(titles[j]))); c != 0 {
+			return c < 0
+		}
+		return titles[i].VideoID < titles[j].VideoID
+	})
+
+	suggestions := &models.Suggestions{
+		Titles:     []models.Suggestion{},
+		Creators:   []models.Suggestion{},
+		Categories: []models.Suggestion{},
+	}
+	seen := map[string]bool{}
+	for _, video := range titles {
+		title := strings.TrimSpace(video.Title)
+		if len(suggestions.Titles) == size || seen[strings.ToLower(title)] {
+			continue
+		}
+		seen[strings.ToLower(title)] = true
+		suggestions.Titles = append(suggestions.Titles, models.Suggestion{Text: title, VideoID: video.VideoID})
+	}
+	for _, creator := range topCreators(creators, size) {
+		suggestions.Creators = append(suggestions.Creators, models.Suggestion{Text: creator.Name, CreatorID: creator.CreatorID, Count: creator.Count})
+	}
+	for _, category := range topCategories(categories, size) {
+		suggestions.Categories = append(suggestions.Categories, models.Suggestion{Text: category.Name, Count: category.Count})
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// UpdateVideoCategories selects the videos like SearchVideos and applies the update at
+// once, so the returned task has already completed
+func (r *MemoryVideoRepository) UpdateVideoCategories(ctx context.Context, update models.CategoryUpdate) (*models.CategoryTask, error) {
+	r.mu.RLock()
+	size := len(r.videos)
+	r.mu.RUnlock()
+
+	search := update.Search
+	search.Sort = models.SortRelevance
+	search.Page = models.Page{Size: size}
+	result, err := r.SearchVideos(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task := &models.CategoryTask{Completed: true, Total: len(result.Hits)}
+	change := models.CategoryChange{Add: update.Add, Remove: update.Remove}
+	for _, hit := range result.Hits {
+		video, ok := r.videos[hit.Video.VideoID]
+		if !ok {
+			task.VersionConflicts++
+			continue
+		}
+		categories := change.Categories(video.Categories)
+		if models.EqualCategories(categories, video.Categories) {
+			task.Noops++
+			continue
+		}
+		video.Categories = categories
+		task.Updated++
+	}
+
+	r.tasks = append(r.tasks, task)
+	task.ID = strconv.Itoa(len(r.tasks))
+	copied := *task
+	return &copied, nil
+}
+
+func (r *MemoryVideoRepository) ChangeVideoCategories(ctx context.Context, videoID string, change models.CategoryChange) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	video, ok := r.videos[videoID]
+	if !ok {
+		return nil, errs.NotFound("video", videoID)
+	}
+	video.Categories = change.Categories(video.Categories)
+	return append([]string{}, video.Categories...), nil
+}
+
+func (r *MemoryVideoRepository) GetCategoryTask(ctx context.Context, taskID string) (*models.CategoryTask, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n, err := strconv.Atoi(taskID)
+	if err != nil || n < 1 || n > len(r.tasks) {
+		return nil, errs.NotFound("task", taskID)
+	}
+	copied := *r.tasks[n-1]
+	return &copied, nil
+}
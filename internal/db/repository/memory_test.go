@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+func intPtr(v int) *int { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+// testVideos is the catalogue shared by the memory tests and the OpenSearch parity test
+func testVideos() []*models.Video {
+	return []*models.Video{
+		{VideoID: "v1", Title: "How to perform wudu", Description: "Step by step ablution", ViewsCount: "1500", Likes: intPtr(90), UploadDate: "2024-01-10", Categories: []string{"fiqh", "wudu"}, CreatorDetails: models.CreatorDetails{CreatorID: "@alpha", Name: "Alpha"}},
+		{VideoID: "v2", Title: "Salah for beginners", Description: "Learn the prayer", ViewsCount: "4000", Likes: intPtr(300), UploadDate: "2024-03-01", Categories: []string{"fiqh", "salah"}, CreatorDetails: models.CreatorDetails{CreatorID: "@alpha", Name: "Alpha"}},
+		{VideoID: "v3", Title: "Seerah of the Prophet", Description: "The Makkan period", ViewsCount: "2500", Likes: intPtr(120), UploadDate: "2023-11-20", Categories: []string{"seerah"}, CreatorDetails: models.CreatorDetails{CreatorID: "@beta", Name: "Beta"}},
+		{VideoID: "v4", Title: "Short reminder on salah", Description: "One minute", ViewsCount: "800", Likes: intPtr(40), UploadDate: "2024-03-01", IsShort: true, Categories: []string{"salah"}, CreatorDetails: models.CreatorDetails{CreatorID: "@beta", Name: "Beta"}},
+		{VideoID: "v5", Title: "Wudu mistakes", Description: "Common errors in ablution", ViewsCount: "not a number", UploadDate: "2024-02-15", Categories: []string{"wudu"}, CreatorDetails: models.CreatorDetails{CreatorID: "@gamma", Name: "Gamma"}},
+	}
+}
+
+func newTestVideos(t *testing.T) *MemoryVideoRepository {
+	t.Helper()
+	repo := NewMemoryVideoRepository()
+	if _, err := repo.BulkIndexVideos(context.Background(), testVideos()); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func hitIDs(hits []*models.VideoHit) []string {
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.VideoID
+	}
+	return ids
+}
+
+// searchTests lists searches over testVideos with the hits expected in order
+var searchTests = []struct {
+	name   string
+	search models.VideoSearch
+	want   []string
+}{
+	{"newest first, ties by ID", models.VideoSearch{Sort: models.SortNewest}, []string{"v2", "v4", "v5", "v1", "v3"}},
+	{"most viewed skips malformed counts last", models.VideoSearch{Sort: models.SortMostViewed}, []string{"v2", "v3", "v1", "v4", "v5"}},
+	{"most liked", models.VideoSearch{Sort: models.SortMostLiked}, []string{"v2", "v3", "v1", "v4", "v5"}},
+	{"query matches title, description and categories", models.VideoSearch{Query: "ablution", Sort: models.SortNewest}, []string{"v5", "v1"}},
+	{"typo within the edit distance", models.VideoSearch{Query: "wuddu", Sort: models.SortNewest, Match: models.TextMatch{Fuzziness: "AUTO"}}, []string{"v5", "v1"}},
+	{"typo without fuzziness", models.VideoSearch{Query: "wuddu", Sort: models.SortNewest}, []string{}},
+	{"any category", models.VideoSearch{Sort: models.SortNewest, Filters: models.SearchFilters{CategoriesAny: []string{"seerah", "wudu"}}}, []string{"v5", "v1", "v3"}},
+	{"all categories", models.VideoSearch{Sort: models.SortNewest, Filters: models.SearchFilters{CategoriesAll: []string{"fiqh", "salah"}}}, []string{"v2"}},
+	{"creator", models.VideoSearch{Sort: models.SortNewest, Filters: models.SearchFilters{CreatorID: "@beta"}}, []string{"v4", "v3"}},
+	{"shorts only", models.VideoSearch{Sort: models.SortNewest, Filters: models.SearchFilters{IsShort: boolPtr(true)}}, []string{"v4"}},
+	{"upload window includes the whole end day", models.VideoSearch{Sort: models.SortNewest, Filters: models.SearchFilters{UploadedAfter: "2024-01-10", UploadedBefore: "2024-03-01"}}, []string{"v2", "v4", "v5", "v1"}},
+	{"minimum views", models.VideoSearch{Sort: models.SortNewest, Filters: models.SearchFilters{MinViews: 2000}}, []string{"v2", "v3"}},
+}
+
+func TestMemorySearchVideos(t *testing.T) {
+	repo := newTestVideos(t)
+
+	for _, tt := range searchTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.search.Page = models.Page{Size: 10}
+			result, err := repo.SearchVideos(context.Background(), tt.search)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hitIDs(result.Hits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hits = %v, want %v", got, tt.want)
+			}
+			if result.Total != len(tt.want) {
+				t.Errorf("total = %d, want %d", result.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestMemorySearchFacetsIgnoreOwnFilter(t *testing.T) {
+	repo := newTestVideos(t)
+
+	result, err := repo.SearchVideos(context.Background(), models.VideoSearch{
+		Sort:    models.SortNewest,
+		Page:    models.Page{Size: 10},
+		Filters: models.SearchFilters{CategoriesAny: []string{"wudu"}, CreatorID: "@alpha"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitIDs(result.Hits); !reflect.DeepEqual(got, []string{"v1"}) {
+		t.Fatalf("hits = %v, want [v1]", got)
+	}
+
+	// The category facet keeps the creator filter only, the creator facet the category filter only
+	wantCategories := []models.CategoryCount{{Name: "fiqh", Count: 2}, {Name: "salah", Count: 1}, {Name: "wudu", Count: 1}}
+	if !reflect.DeepEqual(result.Facets.Categories, wantCategories) {
+		t.Errorf("category facet = %+v, want %+v", result.Facets.Categories, wantCategories)
+	}
+	wantCreators := []models.CreatorCount{{CreatorID: "@alpha", Name: "Alpha", Count: 1}, {CreatorID: "@gamma", Name: "Gamma", Count: 1}}
+	if !reflect.DeepEqual(result.Facets.Creators, wantCreators) {
+		t.Errorf("creator facet = %+v, want %+v", result.Facets.Creators, wantCreators)
+	}
+}
+
+func TestMemoryPagination(t *testing.T) {
+	repo := newTestVideos(t)
+
+	for _, order := range []string{models.SortRelevance, models.SortNewest, models.SortMostViewed, models.SortMostLiked} {
+		t.Run(order, func(t *testing.T) {
+			search := models.VideoSearch{Sort: order, Page: models.Page{Size: 10}}
+			all, err := repo.SearchVideos(context.Background(), search)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := hitIDs(all.Hits)
+
+			// Offset pages
+			var offsets []string
+			for from := 0; from < len(want); from += 2 {
+				search.Page = models.Page{From: from, Size: 2}
+				page, err := repo.SearchVideos(context.Background(), search)
+				if err != nil {
+					t.Fatal(err)
+				}
+				offsets = append(offsets, hitIDs(page.Hits)...)
+			}
+			if !reflect.DeepEqual(offsets, want) {
+				t.Errorf("offset pages = %v, want %v", offsets, want)
+			}
+
+			// Cursor pages, with the cursor going through its token form like a client
+			var cursors []string
+			search.Page = models.Page{Size: 2}
+			for pages := 0; pages <= len(want); pages++ {
+				page, err := repo.SearchVideos(context.Background(), search)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cursors = append(cursors, hitIDs(page.Hits)...)
+				if page.Next == nil {
+					break
+				}
+				cursor, err := models.ParseCursor(page.Next.Encode())
+				if err != nil {
+					t.Fatal(err)
+				}
+				search.Page = models.Page{Size: 2, Cursor: cursor}
+			}
+			if !reflect.DeepEqual(cursors, want) {
+				t.Errorf("cursor pages = %v, want %v", cursors, want)
+			}
+		})
+	}
+}
+
+func TestMemorySearchVideosByCategory(t *testing.T) {
+	repo := newTestVideos(t)
+	// A category sharing words with salah must not match it
+	if _, err := repo.BulkIndexVideos(context.Background(), []*models.Video{{VideoID: "v6", Title: "Fiqh of salah", Categories: []string{"fiqh-salah"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := repo.SearchVideosByCategory(context.Background(), []string{"salah", "seerah"}, models.Page{Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || len(page.Videos) != 2 || page.Next == nil {
+		t.Fatalf("first page: total %d, %d videos, next %v", page.Total, len(page.Videos), page.Next)
+	}
+	rest, err := repo.SearchVideosByCategory(context.Background(), []string{"salah", "seerah"}, models.Page{Size: 2, Cursor: page.Next})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{page.Videos[0].VideoID, page.Videos[1].VideoID}
+	for _, video := range rest.Videos {
+		ids = append(ids, video.VideoID)
+	}
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"v2", "v3", "v4"}) || rest.Next != nil {
+		t.Errorf("videos = %v, next %v, want [v2 v3 v4] and no next page", ids, rest.Next)
+	}
+}
+
+func TestMemoryGetVideosByIDs(t *testing.T) {
+	repo := newTestVideos(t)
+
+	videos, err := repo.GetVideosByIDs(context.Background(), []string{"v3", "missing", "v1", "v3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(videos) != 2 || videos["v1"].Title != "How to perform wudu" || videos["v3"].Title != "Seerah of the Prophet" {
+		t.Errorf("GetVideosByIDs = %v, want v1 and v3", videos)
+	}
+}
+
+func TestMemoryCategoryCounts(t *testing.T) {
+	repo := newTestVideos(t)
+
+	counts, err := repo.CountVideosByCategory(context.Background(), []string{"fiqh", "wudu", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"fiqh": 2, "wudu": 2}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountVideosByCategory = %v, want %v", counts, want)
+	}
+
+	// v1 is in both fiqh and wudu and counts once
+	totals, err := repo.CountVideosInSubtrees(context.Background(), map[string][]string{
+		"fiqh":   {"fiqh", "wudu", "salah"},
+		"seerah": {"seerah"},
+		"empty":  {"missing"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"fiqh": 4, "seerah": 1}; !reflect.DeepEqual(totals, want) {
+		t.Errorf("CountVideosInSubtrees = %v, want %v", totals, want)
+	}
+}
+
+func TestMemoryUpdateVideoCategories(t *testing.T) {
+	repo := newTestVideos(t)
+
+	task, err := repo.UpdateVideoCategories(context.Background(), models.CategoryUpdate{
+		Search: models.VideoSearch{Filters: models.SearchFilters{CategoriesAny: []string{"wudu"}}},
+		Add:    []string{"tahara"},
+		Remove: []string{"wudu"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.Completed || task.Total != 2 || task.Updated != 2 {
+		t.Errorf("task = %+v, want 2 of 2 updated", task)
+	}
+	video, err := repo.GetVideoByID(context.Background(), "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"fiqh", "tahara"}; !reflect.DeepEqual(video.Categories, want) {
+		t.Errorf("categories = %v, want %v", video.Categories, want)
+	}
+
+	stored, err := repo.GetCategoryTask(context.Background(), task.ID)
+	if err != nil || !reflect.DeepEqual(stored, task) {
+		t.Errorf("GetCategoryTask = %+v, %v, want %+v", stored, err, task)
+	}
+}
+
+// foldTests pairs text with the tokens the multilingual analyzer indexes it as
+var foldTests = []struct {
+	name string
+	text string
+	want []string
+}{
+	{"latin diacritics", "Ṣalāh al-Fajr", []string{"salah", "al", "fajr"}},
+	{"arabic harakat and teh marbuta", "صَلَاة", []string{"صلاه"}},
+	{"alef with hamza", "أذان", []string{"اذان"}},
+	{"tatweel", "محـــمد", []string{"محمد"}},
+	{"urdu heh and yeh", "جمعہ کی نماز", []string{"جمعه", "كي", "نماز"}},
+	{"arabic-indic and persian digits", "سورة ١٨ ۴۵", []string{"سوره", "18", "45"}},
+}
+
+func TestAnalyzeFoldsLikeMultilingualAnalyzer(t *testing.T) {
+	for _, tt := range foldTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := analyze(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("analyze(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shaik80/ODIW/config"
+	db "github.com/shaik80/ODIW/internal/db/opensearch/controller"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/metrics"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// Each method runs under the deadline of its kind of operation and records its latency
+// and failures under an operation name, see begin.
+
+// begin starts the named operation with the given deadline, zero meaning none. The
+// returned function must be deferred with the named error result: it releases the
+// deadline, reports context errors as errs.ErrCanceled or errs.ErrTimeout and records
+// the call with metrics.ObserveOpenSearch.
+func begin(ctx context.Context, operation string, timeout time.Duration) (context.Context, func(*error)) {
+	start := time.Now()
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	return ctx, func(err *error) {
+		cancel()
+		*err = errs.Interrupted(*err)
+		metrics.ObserveOpenSearch(operation, start, err)
+	}
+}
+
+// OpenSearchVideoRepository stores videos in the OpenSearch videos index
+type OpenSearchVideoRepository struct {
+	Timeouts config.OperationTimeouts
+}
+
+// NewOpenSearchVideoRepository returns a VideoRepository backed by the global OpenSearch client
+func NewOpenSearchVideoRepository(timeouts config.OperationTimeouts) *OpenSearchVideoRepository {
+	return &OpenSearchVideoRepository{Timeouts: timeouts}
+}
+
+func (r OpenSearchVideoRepository) GetVideoByID(ctx context.Context, videoID string) (video *models.Video, err error) {
+	ctx, done := begin(ctx, "get_video", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetVideoByID(ctx, videoID)
+}
+
+func (r OpenSearchVideoRepository) GetVideosByIDs(ctx context.Context, videoIDs []string) (videos map[string]*models.Video, err error) {
+	ctx, done := begin(ctx, "get_videos", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetVideosByIDs(ctx, videoIDs)
+}
+
+func (r OpenSearchVideoRepository) InsertVideo(ctx context.Context, video *models.Video) (err error) {
+	ctx, done := begin(ctx, "insert_video", r.Timeouts.Write)
+	defer done(&err)
+	return db.InsertVideo(ctx, video)
+}
+
+func (r OpenSearchVideoRepository) UpdateVideo(ctx context.Context, video *models.Video) (err error) {
+	ctx, done := begin(ctx, "update_video", r.Timeouts.Write)
+	defer done(&err)
+	return db.UpdateVideo(ctx, video)
+}
+
+func (r OpenSearchVideoRepository) UpdateVideoMetadata(ctx context.Context, video *models.Video) (err error) {
+	ctx, done := begin(ctx, "update_video_metadata", r.Timeouts.Write)
+	defer done(&err)
+	return db.UpdateVideoMetadata(ctx, video)
+}
+
+func (r OpenSearchVideoRepository) DeleteVideoByID(ctx context.Context, videoID string) (err error) {
+	ctx, done := begin(ctx, "delete_video", r.Timeouts.Write)
+	defer done(&err)
+	return db.DeleteVideoByID(ctx, videoID)
+}
+
+func (r OpenSearchVideoRepository) BulkIndexVideos(ctx context.Context, videos []*models.Video) (results []models.BulkItemResult, err error) {
+	ctx, done := begin(ctx, "bulk_index_videos", r.Timeouts.Bulk)
+	defer done(&err)
+	return db.BulkIndexVideos(ctx, videos)
+}
+
+func (r OpenSearchVideoRepository) SearchVideos(ctx context.Context, search models.VideoSearch) (result *models.VideoSearchResult, err error) {
+	ctx, done := begin(ctx, "search_videos", r.Timeouts.Read)
+	defer done(&err)
+	return db.SearchVideos(ctx, search)
+}
+
+func (r OpenSearchVideoRepository) SearchVideosByCategory(ctx context.Context, categories []string, page models.Page) (result *models.VideoPage, err error) {
+	ctx, done := begin(ctx, "search_videos_by_category", r.Timeouts.Read)
+	defer done(&err)
+	return db.SearchVideosByCategory(ctx, categories, page)
+}
+
+func (r OpenSearchVideoRepository) Suggest(ctx context.Context, query string, size int) (suggestions *models.Suggestions, err error) {
+	ctx, done := begin(ctx, "suggest", r.Timeouts.Read)
+	defer done(&err)
+	return db.Suggest(ctx, query, size)
+}
+
+func (r OpenSearchVideoRepository) CountVideosByCategory(ctx context.Context, slugs []string) (counts map[string]int, err error) {
+	ctx, done := begin(ctx, "count_videos_by_category", r.Timeouts.Read)
+	defer done(&err)
+	return db.CountVideosByCategory(ctx, slugs)
+}
+
+func (r OpenSearchVideoRepository) CountVideosInSubtrees(ctx context.Context, subtrees map[string][]string) (counts map[string]int, err error) {
+	ctx, done := begin(ctx, "count_videos_in_subtrees", r.Timeouts.Read)
+	defer done(&err)
+	return db.CountVideosInSubtrees(ctx, subtrees)
+}
+
+func (r OpenSearchVideoRepository) ChangeVideoCategories(ctx context.Context, videoID string, change models.CategoryChange) (categories []string, err error) {
+	ctx, done := begin(ctx, "change_video_categories", r.Timeouts.Write)
+	defer done(&err)
+	return db.ChangeVideoCategories(ctx, videoID, change)
+}
+
+func (r OpenSearchVideoRepository) UpdateVideoCategories(ctx context.Context, update models.CategoryUpdate) (task *models.CategoryTask, err error) {
+	ctx, done := begin(ctx, "update_video_categories", r.Timeouts.Write)
+	defer done(&err)
+	return db.UpdateVideoCategories(ctx, update)
+}
+
+func (r OpenSearchVideoRepository) GetCategoryTask(ctx context.Context, taskID string) (task *models.CategoryTask, err error) {
+	ctx, done := begin(ctx, "get_category_task", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetCategoryTask(ctx, taskID)
+}
+
+func (r OpenSearchVideoRepository) GetStaleVideos(ctx context.Context, olderThan time.Time, limit int) (videos []*models.Video, err error) {
+	ctx, done := begin(ctx, "get_stale_videos", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetStaleVideos(ctx, olderThan, limit)
+}
+
+func (r OpenSearchVideoRepository) GetVideoStats(ctx context.Context) (stats *models.VideoStats, err error) {
+	ctx, done := begin(ctx, "get_video_stats", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetVideoStats(ctx)
+}
+
+// OpenSearchCreatorRepository stores creators in the OpenSearch creators index
+type OpenSearchCreatorRepository struct {
+	Timeouts config.OperationTimeouts
+}
+
+// NewOpenSearchCreatorRepository returns a CreatorRepository backed by the global OpenSearch client
+func NewOpenSearchCreatorRepository(timeouts config.OperationTimeouts) *OpenSearchCreatorRepository {
+	return &OpenSearchCreatorRepository{Timeouts: timeouts}
+}
+
+func (r OpenSearchCreatorRepository) InsertOrUpdateCreator(ctx context.Context, creator *models.Creator) (err error) {
+	ctx, done := begin(ctx, "insert_or_update_creator", r.Timeouts.Write)
+	defer done(&err)
+	return db.InsertOrUpdateCreator(ctx, creator)
+}
+
+func (r OpenSearchCreatorRepository) GetCreatorByID(ctx context.Context, creatorID string) (creator *models.Creator, err error) {
+	ctx, done := begin(ctx, "get_creator", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetCreatorByID(ctx, creatorID)
+}
+
+func (r OpenSearchCreatorRepository) GetCreators(ctx context.Context, from int, size int) (total int, creators []*models.Creator, err error) {
+	ctx, done := begin(ctx, "get_creators", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetCreators(ctx, from, size)
+}
+
+func (r OpenSearchCreatorRepository) SearchVideosByCreator(ctx context.Context, creator *models.Creator, from int, size int) (total int, videos []*models.Video, err error) {
+	ctx, done := begin(ctx, "search_videos_by_creator", r.Timeouts.Read)
+	defer done(&err)
+	return db.SearchVideosByCreator(ctx, creator, from, size)
+}
+
+// OpenSearchCategoryRepository stores categories in the OpenSearch categories index
+type OpenSearchCategoryRepository struct {
+	Timeouts config.OperationTimeouts
+}
+
+// NewOpenSearchCategoryRepository returns a CategoryRepository backed by the global OpenSearch client
+func NewOpenSearchCategoryRepository(timeouts config.OperationTimeouts) *OpenSearchCategoryRepository {
+	return &OpenSearchCategoryRepository{Timeouts: timeouts}
+}
+
+func (r OpenSearchCategoryRepository) GetCategories(ctx context.Context) (categories []*models.Category, err error) {
+	ctx, done := begin(ctx, "get_categories", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetCategories(ctx)
+}
+
+func (r OpenSearchCategoryRepository) GetCategoryBySlug(ctx context.Context, slug string) (category *models.Category, err error) {
+	ctx, done := begin(ctx, "get_category", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetCategoryBySlug(ctx, slug)
+}
+
+func (r OpenSearchCategoryRepository) CreateCategory(ctx context.Context, category *models.Category) (err error) {
+	ctx, done := begin(ctx, "create_category", r.Timeouts.Write)
+	defer done(&err)
+	return db.CreateCategory(ctx, category)
+}
+
+func (r OpenSearchCategoryRepository) UpdateCategory(ctx context.Context, category *models.Category) (err error) {
+	ctx, done := begin(ctx, "update_category", r.Timeouts.Write)
+	defer done(&err)
+	return db.UpdateCategory(ctx, category)
+}
+
+func (r OpenSearchCategoryRepository) DeleteCategory(ctx context.Context, slug string) (err error) {
+	ctx, done := begin(ctx, "delete_category", r.Timeouts.Write)
+	defer done(&err)
+	return db.DeleteCategory(ctx, slug)
+}
+
+// OpenSearchBannerRepository stores banners in the OpenSearch banners index
+type OpenSearchBannerRepository struct {
+	Timeouts config.OperationTimeouts
+}
+
+// NewOpenSearchBannerRepository returns a BannerRepository backed by the global OpenSearch client
+func NewOpenSearchBannerRepository(timeouts config.OperationTimeouts) *OpenSearchBannerRepository {
+	return &OpenSearchBannerRepository{Timeouts: timeouts}
+}
+
+func (r OpenSearchBannerRepository) GetBanners(ctx context.Context) (banners []*models.Banner, err error) {
+	ctx, done := begin(ctx, "get_banners", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetBanners(ctx)
+}
+
+func (r OpenSearchBannerRepository) GetActiveBanners(ctx context.Context, now time.Time, locale string) (banners []*models.Banner, err error) {
+	ctx, done := begin(ctx, "get_active_banners", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetActiveBanners(ctx, now, locale)
+}
+
+func (r OpenSearchBannerRepository) GetBannerByID(ctx context.Context, id string) (banner *models.Banner, err error) {
+	ctx, done := begin(ctx, "get_banner", r.Timeouts.Read)
+	defer done(&err)
+	return db.GetBannerByID(ctx, id)
+}
+
+func (r OpenSearchBannerRepository) CreateBanner(ctx context.Context, banner *models.Banner) (err error) {
+	ctx, done := begin(ctx, "create_banner", r.Timeouts.Write)
+	defer done(&err)
+	return db.CreateBanner(ctx, banner)
+}
+
+func (r OpenSearchBannerRepository) UpdateBanner(ctx context.Context, banner *models.Banner) (err error) {
+	ctx, done := begin(ctx, "update_banner", r.Timeouts.Write)
+	defer done(&err)
+	return db.UpdateBanner(ctx, banner)
+}
+
+func (r OpenSearchBannerRepository) DeleteBanner(ctx context.Context, id string) (err error) {
+	ctx, done := begin(ctx, "delete_banner", r.Timeouts.Write)
+	defer done(&err)
+	return db.DeleteBanner(ctx, id)
+}
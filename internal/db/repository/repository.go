@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// VideoRepository is the storage used by the video handlers
+type VideoRepository interface {
+	GetVideoByID(ctx context.Context, videoID string) (*models.Video, error)
+	// GetVideosByIDs returns the stored videos among videoIDs keyed by ID, leaving out the
+	// missing ones
+	GetVideosByIDs(ctx context.Context, videoIDs []string) (map[string]*models.Video, error)
+	InsertVideo(ctx context.Context, video *models.Video) error
+	UpdateVideo(ctx context.Context, video *models.Video) error
+	// UpdateVideoMetadata updates every field of a stored video but its categories, which
+	// keep any change made since the video was read, and fails with errs.ErrNotFound when
+	// the video is missing
+	UpdateVideoMetadata(ctx context.Context, video *models.Video) error
+	DeleteVideoByID(ctx context.Context, videoID string) error
+	// BulkIndexVideos writes many videos at once and reports the outcome per video
+	BulkIndexVideos(ctx context.Context, videos []*models.Video) ([]models.BulkItemResult, error)
+	// SearchVideos runs a filtered full text search and returns the requested page with the
+	// total hit count and the category and creator facets
+	SearchVideos(ctx context.Context, search models.VideoSearch) (*models.VideoSearchResult, error)
+	// SearchVideosByCategory returns a page of the videos in any of the categories, by offset
+	// or after a cursor
+	SearchVideosByCategory(ctx context.Context, categories []string, page models.Page) (*models.VideoPage, error)
+	// Suggest completes a partially typed query to at most size titles, creators and
+	// categories each
+	Suggest(ctx context.Context, query string, size int) (*models.Suggestions, error)
+	// CountVideosByCategory counts the videos in each of the given category slugs
+	CountVideosByCategory(ctx context.Context, slugs []string) (map[string]int, error)
+	// CountVideosInSubtrees counts for each key the distinct videos in any of its category
+	// slugs, so a video in both a category and its subcategory is counted once
+	CountVideosInSubtrees(ctx context.Context, subtrees map[string][]string) (map[string]int, error)
+	// ChangeVideoCategories edits the categories of one video in place and returns the
+	// resulting categories
+	ChangeVideoCategories(ctx context.Context, videoID string, change models.CategoryChange) ([]string, error)
+	// UpdateVideoCategories starts applying the category update to every matching video and
+	// returns the task tracking it, which may still be running
+	UpdateVideoCategories(ctx context.Context, update models.CategoryUpdate) (*models.CategoryTask, error)
+	// GetCategoryTask reports the progress of a task started by UpdateVideoCategories
+	GetCategoryTask(ctx context.Context, taskID string) (*models.CategoryTask, error)
+	// GetStaleVideos returns videos not refreshed since olderThan, least recently refreshed first
+	GetStaleVideos(ctx context.Context, olderThan time.Time, limit int) ([]*models.Video, error)
+	// GetVideoStats counts all videos and the videos per category
+	GetVideoStats(ctx context.Context) (*models.VideoStats, error)
+}
+
+// CreatorRepository is the storage used by the creator handlers
+type CreatorRepository interface {
+	InsertOrUpdateCreator(ctx context.Context, creator *models.Creator) error
+	GetCreatorByID(ctx context.Context, creatorID string) (*models.Creator, error)
+	GetCreators(ctx context.Context, from int, size int) (int, []*models.Creator, error)
+	SearchVideosByCreator(ctx context.Context, creator *models.Creator, from int, size int) (int, []*models.Video, error)
+}
+
+// CategoryRepository is the storage used by the category handlers
+type CategoryRepository interface {
+	// GetCategories returns every category, hidden ones included, ordered by sort order then slug
+	GetCategories(ctx context.Context) ([]*models.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*models.Category, error)
+	// CreateCategory stores a new category and fails with errs.ErrConflict when the slug is taken
+	CreateCategory(ctx context.Context, category *models.Category) error
+	// UpdateCategory replaces an existing category and fails with errs.ErrNotFound otherwise
+	UpdateCategory(ctx context.Context, category *models.Category) error
+	DeleteCategory(ctx context.Context, slug string) error
+}
+
+// BannerRepository is the storage used by the banner handlers
+type BannerRepository interface {
+	// GetBanners returns every banner, inactive ones included, ordered by position then ID
+	GetBanners(ctx context.Context) ([]*models.Banner, error)
+	// GetActiveBanners returns the enabled banners published at now for locale, ordered by
+	// position then ID, see models.Banner.ActiveAt
+	GetActiveBanners(ctx context.Context, now time.Time, locale string) ([]*models.Banner, error)
+	GetBannerByID(ctx context.Context, id string) (*models.Banner, error)
+	// CreateBanner stores a new banner and fails with errs.ErrConflict when the ID is taken
+	CreateBanner(ctx context.Context, banner *models.Banner) error
+	// UpdateBanner replaces an existing banner and fails with errs.ErrNotFound otherwise
+	UpdateBanner(ctx context.Context, banner *models.Banner) error
+	DeleteBanner(ctx context.Context, id string) error
+}
@@ -0,0 +1,103 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors describing what went wrong, independent of the message.
+// Check them with errors.Is.
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrConflict            = errors.New("conflict")
+	ErrValidation          = errors.New("validation failed")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrStorageUnavailable  = errors.New("storage unavailable")
+	ErrUnauthenticated     = errors.New("unauthenticated")
+	ErrForbidden           = errors.New("forbidden")
+	ErrCanceled            = errors.New("canceled")
+	ErrTimeout             = errors.New("timed out")
+)
+
+// Error is a domain error carrying one of the sentinel kinds, a client facing
+// message and the underlying cause, if any.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+// Error returns the message followed by the cause
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap exposes both the kind and the cause to errors.Is and errors.As
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// NotFound reports that the resource with the given ID does not exist
+func NotFound(resource, id string) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf("%s with ID %s not found", resource, id)}
+}
+
+// Conflict reports that the request clashes with the current state of a resource
+func Conflict(format string, args ...interface{}) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validation reports invalid input
+func Validation(format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// Upstream reports that an external service such as the metadata provider failed
+func Upstream(err error, format string, args ...interface{}) error {
+	return &Error{Kind: ErrUpstreamUnavailable, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// Storage reports that OpenSearch could not be reached or failed to serve the request
+func Storage(err error, format string, args ...interface{}) error {
+	return &Error{Kind: ErrStorageUnavailable, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// Unauthenticated reports missing or invalid credentials
+func Unauthenticated(format string, args ...interface{}) error {
+	return &Error{Kind: ErrUnauthenticated, Message: fmt.Sprintf(format, args...)}
+}
+
+// Forbidden reports valid credentials without the required permission
+func Forbidden(format string, args ...interface{}) error {
+	return &Error{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+// Interrupted reports an error caused by a cancelled or expired context as ErrCanceled
+// or ErrTimeout, keeping err as the cause. Any other error is returned unchanged.
+func Interrupted(err error) error {
+	switch {
+	case err == nil, errors.Is(err, ErrCanceled), errors.Is(err, ErrTimeout):
+		return err
+	case errors.Is(err, context.Canceled):
+		return &Error{Kind: ErrCanceled, Message: "the request was canceled", Err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Kind: ErrTimeout, Message: "the operation timed out", Err: err}
+	}
+	return err
+}
+
+// Message returns the client facing message of a domain error, or fallback for any other error
+func Message(err error, fallback string) string {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Message
+	}
+	return fallback
+}
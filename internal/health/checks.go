@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/db/opensearch/schema"
+	"github.com/shaik80/ODIW/internal/metadata"
+)
+
+// OpenSearchCheck fails until ready returns true and then while the cluster is red.
+// ready guards connect.Client, which is only safe to use once it reports true.
+func OpenSearchCheck(ready func() bool) Check {
+	return Check{Name: "opensearch", Critical: true, Run: func(ctx context.Context) error {
+		if !ready() {
+			return errors.New("OpenSearch client is not initialized")
+		}
+		resp, err := connect.Client.Cluster.Health(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("cluster health: %w", err)
+		}
+		if resp.Status == "red" {
+			return errors.New("cluster health is red")
+		}
+		return nil
+	}}
+}
+
+// IndicesCheck fails while one of the managed indices is missing
+func IndicesCheck(ready func() bool) Check {
+	return Check{Name: "indices", Critical: true, Run: func(ctx context.Context) error {
+		if !ready() {
+			return errors.New("OpenSearch client is not initialized")
+		}
+		var missing []string
+		for _, index := range schema.All() {
+			resp, err := connect.Client.Indices.Exists(ctx, opensearchapi.IndicesExistsReq{Indices: []string{index.Alias}})
+			if err == nil {
+				continue
+			}
+			if resp == nil || resp.StatusCode != http.StatusNotFound {
+				return fmt.Errorf("checking index %s: %w", index.Alias, err)
+			}
+			missing = append(missing, index.Alias)
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing indices: %v", missing)
+		}
+		return nil
+	}}
+}
+
+// MetadataCheck reports whether the metadata provider is reachable. It is not critical:
+// reads keep working while the provider is down.
+func MetadataCheck(provider metadata.VideoMetadataProvider) Check {
+	return Check{Name: "metadata", Run: func(ctx context.Context) error {
+		return metadata.Ping(ctx, provider)
+	}}
+}
@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"context"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// LegacyBannerCategory is the category that made up the carousel before banners were
+// managed on their own
+const LegacyBannerCategory = "banner"
+
+// ImportCategoryBanners creates an enabled banner for every video tagged with category,
+// placed after the existing banners in the order the category carousel showed them.
+// Videos that already have a banner are skipped, so running it again is safe. It returns
+// the number of banners created.
+func ImportCategoryBanners(ctx context.Context, videos repository.VideoRepository, banners repository.BannerRepository, category string) (int, error) {
+	existing, err := banners.GetBanners(ctx)
+	if err != nil {
+		return 0, err
+	}
+	position := 0
+	bannered := map[string]bool{}
+	for _, banner := range existing {
+		bannered[banner.VideoID] = true
+		if banner.Position >= position {
+			position = banner.Position + 1
+		}
+	}
+
+	created := 0
+	page := models.Page{Size: 100}
+	for {
+		result, err := videos.SearchVideosByCategory(ctx, []string{category}, page)
+		if err != nil {
+			return created, err
+		}
+		for _, video := range result.Videos {
+			if bannered[video.VideoID] {
+				continue
+			}
+			banner := &models.Banner{
+				ID:          models.NewBannerID(),
+				Enabled:     true,
+				VideoID:     video.VideoID,
+				Position:    position,
+				LastUpdated: time.Now().UTC().Format(time.RFC3339),
+			}
+			if err := banners.CreateBanner(ctx, banner); err != nil {
+				return created, err
+			}
+			lp.FromContext(ctx).Info("imported banner", "banner_id", banner.ID, "video_id", video.VideoID, "position", position)
+			bannered[video.VideoID] = true
+			position++
+			created++
+		}
+		if result.Next == nil {
+			return created, nil
+		}
+		page.Cursor = result.Next
+	}
+}
@@ -0,0 +1,328 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/metadata"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// Item statuses reported by Run
+const (
+	StatusCreated   = "created"
+	StatusUpdated   = "updated"
+	StatusDuplicate = "duplicate"
+	StatusFailed    = "failed"
+	// StatusCanceled marks items not finished because the import was canceled or ran out
+	// of time
+	StatusCanceled = "canceled"
+)
+
+const (
+	defaultConcurrency = 4
+	defaultBatchSize   = 500
+)
+
+// ItemResult is the outcome of importing one item
+type ItemResult struct {
+	Line    int    `json:"line"`
+	Input   string `json:"input"`
+	VideoID string `json:"videoId,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report summarises an import. Succeeded counts created and updated videos; repeated
+// entries are merged into the first one and counted as Duplicates.
+type Report struct {
+	Total      int          `json:"total"`
+	Succeeded  int          `json:"succeeded"`
+	Failed     int          `json:"failed"`
+	Duplicates int          `json:"duplicates"`
+	Canceled   int          `json:"canceled"`
+	Items      []ItemResult `json:"items"`
+}
+
+// Importer fetches metadata for many videos and stores them with bulk requests
+type Importer struct {
+	Metadata metadata.VideoMetadataProvider
+	Videos   repository.VideoRepository
+	Creators repository.CreatorRepository
+	// Categories holds the categories the imported videos may be tagged with
+	Categories repository.CategoryRepository
+	// AutoCreateCategories creates the categories that do not exist yet instead of failing
+	// the items tagged with them
+	AutoCreateCategories bool
+	// Concurrency bounds the number of metadata requests in flight
+	Concurrency int
+	// BatchSize is the number of videos written per bulk request
+	BatchSize int
+}
+
+// New returns an Importer with the default concurrency and batch size
+func New(provider metadata.VideoMetadataProvider, videos repository.VideoRepository, creators repository.CreatorRepository, categories repository.CategoryRepository) *Importer {
+	return &Importer{
+		Metadata:    provider,
+		Videos:      videos,
+		Creators:    creators,
+		Categories:  categories,
+		Concurrency: defaultConcurrency,
+		BatchSize:   defaultBatchSize,
+	}
+}
+
+// Run imports the items and reports the outcome of each one in input order
+func (im *Importer) Run(ctx context.Context, items []Item) Report {
+	results := make([]ItemResult, len(items))
+	unique := []int{}
+	firstByID := map[string]int{}
+
+	for i, item := range items {
+		results[i] = ItemResult{Line: item.Line, Input: item.Input, VideoID: item.VideoID}
+		if item.Error != "" {
+			results[i].Status, results[i].Error = StatusFailed, item.Error
+			continue
+		}
+		if first, ok := firstByID[item.VideoID]; ok {
+			items[first].Categories = cleanCategories(append(items[first].Categories, item.Categories...))
+			results[i].Status = StatusDuplicate
+			results[i].Error = fmt.Sprintf("merged into line %d", items[first].Line)
+			continue
+		}
+		firstByID[item.VideoID] = i
+		unique = append(unique, i)
+	}
+	unique = im.checkCategories(ctx, items, unique, results)
+
+	batchSize := im.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	// Write each batch as soon as it has been fetched. The writes do not stop with ctx, so
+	// an import that is canceled or runs out of time still stores what it fetched; the
+	// repositories bound each write by their own timeouts.
+	writeCtx := context.WithoutCancel(ctx)
+	videos := make([]*models.Video, len(items))
+	var stored, pending []int
+	for i := range im.fetchAll(ctx, items, unique, results, videos) {
+		pending = append(pending, i)
+		if len(pending) == batchSize {
+			im.writeBatch(writeCtx, pending, videos, results)
+			stored = append(stored, pending...)
+			pending = nil
+		}
+	}
+	if len(pending) > 0 {
+		im.writeBatch(writeCtx, pending, videos, results)
+		stored = append(stored, pending...)
+	}
+
+	im.storeCreators(writeCtx, stored, videos, results)
+
+	report := Report{Total: len(items), Items: results}
+	for _, result := range results {
+		switch result.Status {
+		case StatusCreated, StatusUpdated:
+			report.Succeeded++
+		case StatusDuplicate:
+			report.Duplicates++
+		case StatusCanceled:
+			report.Canceled++
+		default:
+			report.Failed++
+		}
+	}
+	lp.FromContext(ctx).Info("import finished", "total", report.Total, "succeeded", report.Succeeded, "failed", report.Failed, "duplicates", report.Duplicates, "canceled", report.Canceled)
+	return report
+}
+
+// checkCategories fails the items tagged with malformed or unknown categories, unless
+// AutoCreateCategories creates the unknown ones, and returns the items left to import
+func (im *Importer) checkCategories(ctx context.Context, items []Item, unique []int, results []ItemResult) []int {
+	stored, err := im.Categories.GetCategories(ctx)
+	if err != nil {
+		for _, i := range unique {
+			results[i].Status, results[i].Error = failureStatus(err), err.Error()
+		}
+		return nil
+	}
+	known := map[string]bool{}
+	for _, category := range stored {
+		known[category.Slug] = true
+	}
+
+	var checked []int
+	for _, i := range unique {
+		if err := im.knownCategories(ctx, items[i].Categories, known); err != nil {
+			results[i].Status, results[i].Error = failureStatus(err), err.Error()
+			continue
+		}
+		checked = append(checked, i)
+	}
+	return checked
+}
+
+// knownCategories checks the categories of one item against known, creating the missing
+// ones named after their slugs with AutoCreateCategories. A category created concurrently
+// by a request is fine.
+func (im *Importer) knownCategories(ctx context.Context, categories []string, known map[string]bool) error {
+	var missing []string
+	for _, slug := range categories {
+		if err := models.ValidateSlug(slug); err != nil {
+			return err
+		}
+		if !known[slug] {
+			missing = append(missing, slug)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if !im.AutoCreateCategories {
+		return errs.Validation("unknown categories %s, create them first or set auto_create_categories", strings.Join(missing, ", "))
+	}
+
+	for _, slug := range missing {
+		category := &models.Category{
+			Slug:        slug,
+			Names:       map[string]string{models.DefaultLanguage: slug},
+			LastUpdated: time.Now().UTC().Format(time.RFC3339),
+		}
+		if err := im.Categories.CreateCategory(ctx, category); err != nil && !errors.Is(err, errs.ErrConflict) {
+			return err
+		}
+		lp.FromContext(ctx).Info("created category for import", "slug", slug)
+		known[slug] = true
+	}
+	return nil
+}
+
+// fetchAll loads the metadata of the unique items into videos with bounded concurrency.
+// It sends the index of each fetched item on the returned channel, which is closed once
+// every item is done.
+func (im *Importer) fetchAll(ctx context.Context, items []Item, unique []int, results []ItemResult, videos []*models.Video) <-chan int {
+	concurrency := im.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	fetched := make(chan int)
+	go func() {
+		defer close(fetched)
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for _, i := range unique {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				video, err := im.prepare(ctx, items[i])
+				if err != nil {
+					results[i].Status, results[i].Error = failureStatus(err), err.Error()
+					return
+				}
+				videos[i] = video
+				fetched <- i
+			}(i)
+		}
+		wg.Wait()
+	}()
+	return fetched
+}
+
+// prepare fetches and validates one video, keeping the categories it already has
+func (im *Importer) prepare(ctx context.Context, item Item) (*models.Video, error) {
+	video, err := im.Metadata.FetchVideo(ctx, item.VideoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ValidateVideo(video); err != nil {
+		return nil, err
+	}
+	video.VideoID = item.VideoID
+	video.RefreshedAt = time.Now().UTC().Format(time.RFC3339)
+
+	existing, err := im.Videos.GetVideoByID(ctx, item.VideoID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		video.Categories = cleanCategories(append(existing.Categories, item.Categories...))
+	} else {
+		video.Categories = item.Categories
+	}
+
+	if video.CreatorDetails.CreatorID == "" {
+		video.CreatorDetails.CreatorID = models.CreatorIDFromChannelLink(video.CreatorDetails.ChannelLink)
+	}
+	return video, nil
+}
+
+// writeBatch bulk indexes one batch and records the per item outcome
+func (im *Importer) writeBatch(ctx context.Context, batch []int, videos []*models.Video, results []ItemResult) {
+	docs := make([]*models.Video, len(batch))
+	for j, i := range batch {
+		docs[j] = videos[i]
+	}
+
+	bulkResults, err := im.Videos.BulkIndexVideos(ctx, docs)
+	if err != nil {
+		for _, i := range batch {
+			results[i].Status, results[i].Error = failureStatus(err), err.Error()
+			videos[i] = nil
+		}
+		return
+	}
+
+	for j, i := range batch {
+		if bulkResults[j].Error != "" {
+			results[i].Status, results[i].Error = StatusFailed, bulkResults[j].Error
+			videos[i] = nil
+			continue
+		}
+		results[i].Status = StatusCreated
+		if bulkResults[j].Result == StatusUpdated {
+			results[i].Status = StatusUpdated
+		}
+	}
+}
+
+// storeCreators upserts each distinct creator of the stored videos. Failures are only logged
+// because the videos themselves were imported.
+func (im *Importer) storeCreators(ctx context.Context, stored []int, videos []*models.Video, results []ItemResult) {
+	seen := map[string]bool{}
+	for _, i := range stored {
+		video := videos[i]
+		if video == nil || video.CreatorDetails.CreatorID == "" || seen[video.CreatorDetails.CreatorID] {
+			continue
+		}
+		seen[video.CreatorDetails.CreatorID] = true
+
+		creator := models.CreatorFromDetails(video.CreatorDetails)
+		if err := im.Creators.InsertOrUpdateCreator(ctx, &creator); err != nil {
+			lp.FromContext(ctx).Warn("failed to store creator", "creator_id", creator.CreatorID, "video_id", results[i].VideoID, "error", err)
+		}
+	}
+}
+
+// failureStatus tells items canceled with the import or cut off by its deadline apart
+// from failed ones
+func failureStatus(err error) string {
+	switch {
+	case errors.Is(err, errs.ErrCanceled), errors.Is(err, errs.ErrTimeout),
+		errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return StatusCanceled
+	}
+	return StatusFailed
+}
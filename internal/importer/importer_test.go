@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// slowProvider returns the metadata of every video right away except for block, whose
+// fetch lasts until ctx is done
+type slowProvider struct {
+	block string
+}
+
+func (p slowProvider) FetchVideo(ctx context.Context, videoID string) (*models.Video, error) {
+	if videoID == p.block {
+		<-ctx.Done()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, errs.Interrupted(err)
+	}
+	return &models.Video{VideoID: videoID, Title: "Video " + videoID}, nil
+}
+
+// contextVideos fails bulk writes on a done context, as the OpenSearch repository does
+type contextVideos struct {
+	*repository.MemoryVideoRepository
+}
+
+func (r contextVideos) BulkIndexVideos(ctx context.Context, videos []*models.Video) ([]models.BulkItemResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errs.Interrupted(err)
+	}
+	return r.MemoryVideoRepository.BulkIndexVideos(ctx, videos)
+}
+
+func TestRunStoresFetchedVideosAfterDeadline(t *testing.T) {
+	memory := repository.NewMemoryVideoRepository()
+	im := &Importer{
+		Metadata:    slowProvider{block: "v4"},
+		Videos:      contextVideos{memory},
+		Creators:    repository.NewMemoryCreatorRepository(memory),
+		Categories:  repository.NewMemoryCategoryRepository(),
+		Concurrency: 1,
+		BatchSize:   2,
+	}
+	var items []Item
+	for i, id := range []string{"v1", "v2", "v3", "v4", "v5"} {
+		items = append(items, Item{Line: i + 1, Input: id, VideoID: id})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	report := im.Run(ctx, items)
+
+	want := []string{StatusCreated, StatusCreated, StatusCreated, StatusCanceled, StatusCanceled}
+	for i, result := range report.Items {
+		if result.Status != want[i] {
+			t.Errorf("%s status = %s (%s), want %s", result.VideoID, result.Status, result.Error, want[i])
+		}
+	}
+	if report.Succeeded != 3 || report.Canceled != 2 || report.Failed != 0 {
+		t.Errorf("report = %d succeeded, %d canceled, %d failed, want 3, 2 and 0", report.Succeeded, report.Canceled, report.Failed)
+	}
+	for _, id := range []string{"v1", "v2", "v3"} {
+		if _, err := memory.GetVideoByID(context.Background(), id); err != nil {
+			t.Errorf("fetched video %s was not stored: %v", id, err)
+		}
+	}
+}
+
+func TestRunChecksCategories(t *testing.T) {
+	ctx := context.Background()
+	items := []Item{
+		{Line: 1, Input: "v1", VideoID: "v1", Categories: []string{"fiqh"}},
+		{Line: 2, Input: "v2", VideoID: "v2", Categories: []string{"fiqh", "seerah"}},
+		{Line: 3, Input: "v3", VideoID: "v3", Categories: []string{"Seerah"}},
+	}
+	tests := []struct {
+		name       string
+		autoCreate bool
+		want       []string
+	}{
+		{name: "unknown categories fail", want: []string{StatusCreated, StatusFailed, StatusFailed}},
+		{name: "unknown categories created", autoCreate: true, want: []string{StatusCreated, StatusCreated, StatusFailed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			videos := repository.NewMemoryVideoRepository()
+			categories := repository.NewMemoryCategoryRepository()
+			if err := categories.CreateCategory(ctx, &models.Category{Slug: "fiqh", Names: map[string]string{models.DefaultLanguage: "Fiqh"}}); err != nil {
+				t.Fatal(err)
+			}
+			im := New(slowProvider{}, videos, repository.NewMemoryCreatorRepository(videos), categories)
+			im.AutoCreateCategories = tt.autoCreate
+
+			report := im.Run(ctx, append([]Item(nil), items...))
+			var got []string
+			for _, result := range report.Items {
+				got = append(got, result.Status)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statuses = %v, want %v (%+v)", got, tt.want, report.Items)
+			}
+			_, err := categories.GetCategoryBySlug(ctx, "seerah")
+			if created := err == nil; created != tt.autoCreate {
+				t.Errorf("seerah created = %t, want %t", created, tt.autoCreate)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// Item is one entry of an import file. Entries that could not be parsed carry Error
+// and are reported as failed instead of aborting the import.
+type Item struct {
+	Line       int      `json:"line"`
+	Input      string   `json:"input"`
+	VideoID    string   `json:"videoId"`
+	Categories []string `json:"categories"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Supported import formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// FormatFromContentType maps a request content type to an import format, defaulting to NDJSON
+func FormatFromContentType(contentType string) string {
+	if strings.Contains(strings.ToLower(contentType), "csv") {
+		return FormatCSV
+	}
+	return FormatNDJSON
+}
+
+// Parse reads import items in the given format
+func Parse(r io.Reader, format string) ([]Item, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatNDJSON, "jsonl":
+		return ParseNDJSON(r)
+	default:
+		return nil, errs.Validation("unsupported import format %q, use csv or ndjson", format)
+	}
+}
+
+// ParseCSV reads rows of "video,category,category..." where video is a video ID or URL.
+// Categories may also be combined in one column separated by "|" or ";". A header row
+// and lines starting with # are skipped.
+func ParseCSV(r io.Reader) ([]Item, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	var items []Item
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				items = append(items, Item{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, err
+		}
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if len(items) == 0 && isHeader(record[0]) {
+			continue
+		}
+
+		var categories []string
+		for _, field := range record[1:] {
+			categories = append(categories, splitCategories(field)...)
+		}
+		items = append(items, newItem(line, record[0], categories))
+	}
+	return items, nil
+}
+
+// ParseNDJSON reads one JSON object per line with video_id (or url) and categories
+func ParseNDJSON(r io.Reader) ([]Item, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var items []Item
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var entry struct {
+			VideoID    string   `json:"video_id"`
+			URL        string   `json:"url"`
+			Categories []string `json:"categories"`
+		}
+		if err := json.Unmarshal([]byte(text), &entry); err != nil {
+			items = append(items, Item{Line: line, Input: text, Error: fmt.Sprintf("invalid JSON: %v", err)})
+			continue
+		}
+
+		input := entry.VideoID
+		if input == "" {
+			input = entry.URL
+		}
+		items = append(items, newItem(line, input, entry.Categories))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func newItem(line int, input string, categories []string) Item {
+	input = strings.TrimSpace(input)
+	item := Item{
+		Line:       line,
+		Input:      input,
+		VideoID:    models.VideoIDFromURL(input),
+		Categories: cleanCategories(categories),
+	}
+	if item.VideoID == "" {
+		item.Error = "video ID or URL is required"
+	}
+	return item
+}
+
+func isHeader(cell string) bool {
+	switch strings.ToLower(strings.TrimSpace(cell)) {
+	case "video", "video_id", "videoid", "id", "url":
+		return true
+	}
+	return false
+}
+
+func splitCategories(field string) []string {
+	return strings.FieldsFunc(field, func(r rune) bool { return r == '|' || r == ';' })
+}
+
+// cleanCategories trims the categories and drops empty and repeated ones
+func cleanCategories(categories []string) []string {
+	cleaned := []string{}
+	seen := map[string]bool{}
+	for _, category := range categories {
+		category = strings.TrimSpace(category)
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		cleaned = append(cleaned, category)
+	}
+	return cleaned
+}
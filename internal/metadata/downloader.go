@@ -0,0 +1,69 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// DefaultDownloaderURL is the video downloader backend used when no base URL is configured
+const DefaultDownloaderURL = "https://yig-video-downloader-backend.vercel.app"
+
+// DownloaderProvider fetches video details from the video downloader backend
+type DownloaderProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewDownloaderProvider returns a provider calling the downloader backend at baseURL
+func NewDownloaderProvider(baseURL string, client *http.Client) *DownloaderProvider {
+	if baseURL == "" {
+		baseURL = DefaultDownloaderURL
+	}
+	return &DownloaderProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Client:  client,
+	}
+}
+
+// Ping checks that the downloader backend answers
+func (p *DownloaderProvider) Ping(ctx context.Context) error {
+	return pingURL(ctx, p.Client, p.BaseURL)
+}
+
+// FetchVideo requests the video info endpoint and decodes the wrapped video
+func (p *DownloaderProvider) FetchVideo(ctx context.Context, videoID string) (*models.Video, error) {
+	query := url.Values{}
+	query.Set("url", "https://www.youtube.com/watch?v="+videoID)
+	query.Set("details", "true")
+	endpoint := fmt.Sprintf("%s/get_youtube_video_info?%s", p.BaseURL, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, errs.Upstream(err, "error requesting video info")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errs.Upstream(nil, "video info endpoint returned %s", resp.Status)
+	}
+
+	var response models.VideoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, errs.Upstream(err, "error decoding video info")
+	}
+
+	video := response.Data
+	video.VideoID = videoID
+	return &video, nil
+}
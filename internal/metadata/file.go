@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// FileProvider reads video details from <dir>/<videoId>.json for offline development and tests.
+// A fixture may hold either the downloader response ({"status":..,"data":{..}}) or a bare video.
+type FileProvider struct {
+	Dir string
+}
+
+// NewFileProvider returns a provider reading fixtures from dir
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{Dir: dir}
+}
+
+// Ping checks that the fixtures directory exists
+func (p *FileProvider) Ping(ctx context.Context) error {
+	info, err := os.Stat(p.Dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", p.Dir)
+	}
+	return nil
+}
+
+// FetchVideo loads the fixture for the video
+func (p *FileProvider) FetchVideo(ctx context.Context, videoID string) (*models.Video, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if videoID != filepath.Base(videoID) {
+		return nil, errs.Validation("invalid video ID %q", videoID)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.Dir, videoID+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errs.NotFound("video fixture", videoID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixture for video %s: %w", videoID, err)
+	}
+
+	var wrapped struct {
+		Data *models.Video `json:"data"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("error decoding fixture for video %s: %w", videoID, err)
+	}
+
+	video := wrapped.Data
+	if video == nil {
+		video = &models.Video{}
+		if err := json.Unmarshal(data, video); err != nil {
+			return nil, fmt.Errorf("error decoding fixture for video %s: %w", videoID, err)
+		}
+	}
+	video.VideoID = videoID
+	return video, nil
+}
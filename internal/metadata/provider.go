@@ -0,0 +1,113 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shaik80/ODIW/config"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/metrics"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// VideoMetadataProvider fetches the details of a YouTube video from an external source
+type VideoMetadataProvider interface {
+	FetchVideo(ctx context.Context, videoID string) (*models.Video, error)
+}
+
+// Pinger is implemented by providers that can check whether their source is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks the source of provider, treating providers without a check as reachable
+func Ping(ctx context.Context, provider VideoMetadataProvider) error {
+	if pinger, ok := provider.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+const defaultTimeout = 15 * time.Second
+
+// NewProvider returns the provider selected in the metadata configuration
+func NewProvider(cfg config.MetadataConfig) (VideoMetadataProvider, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	client := &http.Client{}
+
+	var provider VideoMetadataProvider
+	name := strings.ToLower(cfg.Provider)
+	switch name {
+	case "", "downloader":
+		name = "downloader"
+		provider = NewDownloaderProvider(cfg.BaseURL, client)
+	case "youtube":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("metadata.api_key is required for the youtube provider")
+		}
+		provider = NewYouTubeProvider(cfg.BaseURL, cfg.APIKey, client)
+	case "file":
+		if cfg.FixturesDir == "" {
+			return nil, fmt.Errorf("metadata.fixtures_dir is required for the file provider")
+		}
+		provider = NewFileProvider(cfg.FixturesDir)
+	default:
+		return nil, fmt.Errorf("unknown metadata provider: %s", cfg.Provider)
+	}
+	return Instrument(name, timeout, provider), nil
+}
+
+// instrumentedProvider bounds every fetch by a deadline and records its latency and failures
+type instrumentedProvider struct {
+	name    string
+	timeout time.Duration
+	next    VideoMetadataProvider
+}
+
+// Instrument wraps provider so each fetch gets at most timeout, zero meaning no limit,
+// and is reported under name on the metrics endpoint. Fetches ended by the context fail
+// with errs.ErrCanceled or errs.ErrTimeout.
+func Instrument(name string, timeout time.Duration, provider VideoMetadataProvider) VideoMetadataProvider {
+	return &instrumentedProvider{name: name, timeout: timeout, next: provider}
+}
+
+func (p *instrumentedProvider) Ping(ctx context.Context) error {
+	return Ping(ctx, p.next)
+}
+
+func (p *instrumentedProvider) FetchVideo(ctx context.Context, videoID string) (*models.Video, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	video, err := p.next.FetchVideo(ctx, videoID)
+	err = errs.Interrupted(err)
+	metrics.ObserveMetadataFetch(p.name, start, err)
+	return video, err
+}
+
+// pingURL reports whether the server at url answers at all. Any response below 500
+// counts, since the API roots usually return 404 without a resource.
+func pingURL(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errs.Upstream(err, "%s is unreachable", url)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return errs.Upstream(nil, "%s returned %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// DefaultYouTubeURL is the YouTube Data API v3 endpoint used when no base URL is configured
+const DefaultYouTubeURL = "https://www.googleapis.com/youtube/v3"
+
+// shortMaxDuration is the longest duration reported as a short
+const shortMaxDuration = 60 * time.Second
+
+// YouTubeProvider fetches video details from the YouTube Data API v3
+type YouTubeProvider struct {
+	BaseURL string
+	APIKey  string
+	Client  *http.Client
+}
+
+// NewYouTubeProvider returns a provider calling the YouTube Data API at baseURL with apiKey
+func NewYouTubeProvider(baseURL, apiKey string, client *http.Client) *YouTubeProvider {
+	if baseURL == "" {
+		baseURL = DefaultYouTubeURL
+	}
+	return &YouTubeProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+		Client:  client,
+	}
+}
+
+type youtubeThumbnail struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type youtubeVideoList struct {
+	Items []struct {
+		ID      string `json:"id"`
+		Snippet struct {
+			PublishedAt  string                      `json:"publishedAt"`
+			ChannelID    string                      `json:"channelId"`
+			Title        string                      `json:"title"`
+			Description  string                      `json:"description"`
+			Thumbnails   map[string]youtubeThumbnail `json:"thumbnails"`
+			ChannelTitle string                      `json:"channelTitle"`
+			CategoryID   string                      `json:"categoryId"`
+		} `json:"snippet"`
+		ContentDetails struct {
+			Duration string `json:"duration"`
+		} `json:"contentDetails"`
+		Statistics struct {
+			ViewCount string `json:"viewCount"`
+			LikeCount string `json:"likeCount"`
+		} `json:"statistics"`
+	} `json:"items"`
+}
+
+type youtubeChannelList struct {
+	Items []struct {
+		Snippet struct {
+			Title      string                      `json:"title"`
+			CustomURL  string                      `json:"customUrl"`
+			Thumbnails map[string]youtubeThumbnail `json:"thumbnails"`
+		} `json:"snippet"`
+		Statistics struct {
+			SubscriberCount string `json:"subscriberCount"`
+		} `json:"statistics"`
+	} `json:"items"`
+}
+
+// thumbnailSizes lists the YouTube thumbnail keys from smallest to largest
+var thumbnailSizes = []string{"default", "medium", "high", "standard", "maxres"}
+
+// FetchVideo loads the video and its channel and maps them onto a models.Video
+func (p *YouTubeProvider) FetchVideo(ctx context.Context, videoID string) (*models.Video, error) {
+	var videos youtubeVideoList
+	if err := p.get(ctx, "videos", url.Values{
+		"part": {"snippet,statistics,contentDetails"},
+		"id":   {videoID},
+	}, &videos); err != nil {
+		return nil, err
+	}
+	if len(videos.Items) == 0 {
+		return nil, errs.NotFound("youtube video", videoID)
+	}
+	item := videos.Items[0]
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	video := &models.Video{
+		VideoID:       videoID,
+		Title:         item.Snippet.Title,
+		ViewsCount:    item.Statistics.ViewCount,
+		UploadDate:    item.Snippet.PublishedAt,
+		VideoCategory: item.Snippet.CategoryID,
+		Description:   item.Snippet.Description,
+		LastUpdated:   now,
+		CreatorDetails: models.CreatorDetails{
+			CreatorID:   item.Snippet.ChannelID,
+			Name:        item.Snippet.ChannelTitle,
+			ChannelLink: "https://www.youtube.com/channel/" + item.Snippet.ChannelID,
+			LastUpdated: now,
+		},
+	}
+	for _, size := range thumbnailSizes {
+		if thumb, ok := item.Snippet.Thumbnails[size]; ok {
+			video.Thumbnails = append(video.Thumbnails, models.Thumbnail(thumb))
+		}
+	}
+	if likes, err := strconv.Atoi(item.Statistics.LikeCount); err == nil {
+		video.Likes = &likes
+	}
+	if duration, err := parseISODuration(item.ContentDetails.Duration); err == nil {
+		video.IsShort = duration > 0 && duration <= shortMaxDuration
+	}
+
+	var channels youtubeChannelList
+	if err := p.get(ctx, "channels", url.Values{
+		"part": {"snippet,statistics"},
+		"id":   {item.Snippet.ChannelID},
+	}, &channels); err != nil {
+		return nil, err
+	}
+	if len(channels.Items) > 0 {
+		channel := channels.Items[0]
+		video.CreatorDetails.SubscribersCount, _ = strconv.Atoi(channel.Statistics.SubscriberCount)
+		for _, size := range thumbnailSizes {
+			if thumb, ok := channel.Snippet.Thumbnails[size]; ok {
+				video.CreatorDetails.ProfilePic = thumb.URL
+			}
+		}
+	}
+
+	return video, nil
+}
+
+// Ping checks that the API host answers without spending request quota
+func (p *YouTubeProvider) Ping(ctx context.Context) error {
+	return pingURL(ctx, p.Client, p.BaseURL)
+}
+
+// get calls an API resource and decodes the JSON response into out
+func (p *YouTubeProvider) get(ctx context.Context, resource string, query url.Values, out interface{}) error {
+	query.Set("key", p.APIKey)
+	endpoint := fmt.Sprintf("%s/%s?%s", p.BaseURL, resource, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return errs.Upstream(err, "error requesting YouTube %s", resource)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errs.Upstream(nil, "YouTube %s endpoint returned %s", resource, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errs.Upstream(err, "error decoding YouTube %s", resource)
+	}
+	return nil
+}
+
+var isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseISODuration parses the ISO 8601 durations used by YouTube, e.g. PT1H2M3S
+func parseISODuration(value string) (time.Duration, error) {
+	match := isoDurationPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return 0, err
+		}
+		duration += time.Duration(n) * unit
+	}
+	return duration, nil
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+// Registry is served on the metrics endpoint. Besides the metrics below it holds the Go
+// runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	httpRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "odiw_http_request_duration_seconds",
+		Help: "Duration of HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	opensearchDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "odiw_opensearch_request_duration_seconds",
+		Help: "Duration of OpenSearch calls by operation.",
+	}, []string{"operation"})
+	opensearchErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "odiw_opensearch_errors_total",
+		Help: "Failed OpenSearch calls by operation. Not found results and cancellations are not counted.",
+	}, []string{"operation"})
+	opensearchCanceled = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "odiw_opensearch_canceled_total",
+		Help: "OpenSearch calls abandoned because the caller canceled them, by operation.",
+	}, []string{"operation"})
+
+	metadataFetchDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "odiw_metadata_fetch_duration_seconds",
+		Help: "Duration of video metadata fetches by provider.",
+	}, []string{"provider"})
+	metadataFetchFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "odiw_metadata_fetch_failures_total",
+		Help: "Failed video metadata fetches by provider and reason.",
+	}, []string{"provider", "reason"})
+
+	// VideosTotal is the number of stored videos, updated on every scrape
+	VideosTotal = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "odiw_videos",
+		Help: "Number of stored videos.",
+	})
+	// CategoryVideos is the number of videos per category, updated on every scrape
+	CategoryVideos = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "odiw_category_videos",
+		Help: "Number of stored videos per category.",
+	}, []string{"category"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveHTTP records a served request. Route is the registered route pattern, not the
+// raw path, to keep the number of series bounded.
+func ObserveHTTP(method, route string, status int, elapsed time.Duration) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(elapsed.Seconds())
+}
+
+// ObserveOpenSearch records an OpenSearch call started at start. Use it with a named error:
+//
+//	defer metrics.ObserveOpenSearch("get_video", time.Now(), &err)
+func ObserveOpenSearch(operation string, start time.Time, err *error) {
+	opensearchDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err == nil || *err == nil || errors.Is(*err, errs.ErrNotFound) {
+		return
+	}
+	if errors.Is(*err, errs.ErrCanceled) || errors.Is(*err, context.Canceled) {
+		opensearchCanceled.WithLabelValues(operation).Inc()
+		return
+	}
+	opensearchErrors.WithLabelValues(operation).Inc()
+}
+
+// ObserveMetadataFetch records a metadata fetch started at start
+func ObserveMetadataFetch(provider string, start time.Time, err error) {
+	metadataFetchDuration.WithLabelValues(provider).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metadataFetchFailures.WithLabelValues(provider, failureReason(err)).Inc()
+	}
+}
+
+// failureReason maps an error to a low cardinality label value
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, errs.ErrValidation):
+		return "invalid"
+	case errors.Is(err, errs.ErrCanceled), errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, errs.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, errs.ErrUpstreamUnavailable):
+		return "upstream"
+	}
+	return "error"
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+// Banner is a slot of the home page carousel. It shows a video, a custom image or a video
+// with a custom image, between the optional publish times and in the target locales.
+type Banner struct {
+	ID string `json:"id"`
+	// Enabled turns the slot off without losing its schedule. Banners decoded without it,
+	// such as the ones stored before it existed, are enabled.
+	Enabled  bool   `json:"enabled"`
+	VideoID  string `json:"videoId,omitempty"`
+	ImageURL string `json:"imageUrl,omitempty"`
+	// Title replaces the title of the video
+	Title string `json:"title,omitempty"`
+	// LinkURL and LinkLabel make up the call to action
+	LinkURL   string `json:"linkUrl,omitempty"`
+	LinkLabel string `json:"linkLabel,omitempty"`
+	// Position orders the slots, lowest first
+	Position int `json:"position"`
+	// StartsAt and EndsAt bound the publish window as RFC 3339 timestamps or dates, the
+	// end excluded. Empty bounds are open.
+	StartsAt string `json:"startsAt,omitempty"`
+	EndsAt   string `json:"endsAt,omitempty"`
+	// Locales lists the locales the slot targets, such as en or ar, in lowercase. Empty
+	// targets all.
+	Locales     []string `json:"locales,omitempty"`
+	LastUpdated string   `json:"lastUpdated,omitempty"`
+}
+
+// BannerSlot is an active banner as served to the carousel, with the referenced video
+type BannerSlot struct {
+	*Banner
+	Video *Video `json:"video,omitempty"`
+}
+
+// NewBannerID returns a random banner ID
+func NewBannerID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// ValidateBanner validates the banner data and lowercases its locales
+func ValidateBanner(banner *Banner) error {
+	if banner.VideoID == "" && banner.ImageURL == "" {
+		return errs.Validation("videoId or imageUrl is required")
+	}
+	if banner.ImageURL != "" && !strings.HasPrefix(banner.ImageURL, "https://") && !strings.HasPrefix(banner.ImageURL, "http://") {
+		return errs.Validation("imageUrl must be an http or https URL")
+	}
+	if banner.LinkURL != "" && !strings.HasPrefix(banner.LinkURL, "https://") && !strings.HasPrefix(banner.LinkURL, "http://") && !strings.HasPrefix(banner.LinkURL, "/") {
+		return errs.Validation("linkUrl must be an http or https URL or a path")
+	}
+	if banner.Position < 0 {
+		return errs.Validation("position must not be negative")
+	}
+	starts, ends, err := banner.Window()
+	if err != nil {
+		return err
+	}
+	if !starts.IsZero() && !ends.IsZero() && !starts.Before(ends) {
+		return errs.Validation("startsAt must be earlier than endsAt")
+	}
+	for i, locale := range banner.Locales {
+		locale = strings.ToLower(strings.TrimSpace(locale))
+		if locale == "" {
+			return errs.Validation("locales must not contain empty values")
+		}
+		banner.Locales[i] = locale
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a banner, enabling it unless the data says otherwise
+func (b *Banner) UnmarshalJSON(data []byte) error {
+	type banner Banner
+	decoded := banner{Enabled: true}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*b = Banner(decoded)
+	return nil
+}
+
+// Window returns the publish window. Zero times are unbounded.
+func (b *Banner) Window() (starts time.Time, ends time.Time, err error) {
+	if b.StartsAt != "" {
+		if starts, err = ParseDate(b.StartsAt); err != nil {
+			return starts, ends, errs.Validation("startsAt %q is not a date or RFC 3339 timestamp", b.StartsAt)
+		}
+	}
+	if b.EndsAt != "" {
+		if ends, err = ParseDate(b.EndsAt); err != nil {
+			return starts, ends, errs.Validation("endsAt %q is not a date or RFC 3339 timestamp", b.EndsAt)
+		}
+	}
+	return starts, ends, nil
+}
+
+// ActiveAt reports whether the banner is enabled and published at now for locale. A
+// target locale such as ar also covers regional variants such as ar-SA. A banner with a
+// malformed window, stored before the dates were validated, is never active.
+func (b *Banner) ActiveAt(now time.Time, locale string) bool {
+	starts, ends, err := b.Window()
+	if err != nil || !b.Enabled || !starts.IsZero() && now.Before(starts) || !ends.IsZero() && !now.Before(ends) {
+		return false
+	}
+	if len(b.Locales) == 0 {
+		return true
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	for _, target := range b.Locales {
+		if strings.EqualFold(target, locale) || strings.EqualFold(target, language) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+// DefaultLanguage is the language whose display name is used when the requested one is missing
+const DefaultLanguage = "en"
+
+// Category is a curated video category. Videos refer to categories by slug.
+type Category struct {
+	Slug string `json:"slug"`
+	// Parent is the slug of the parent category, empty for top level categories
+	Parent string `json:"parent,omitempty"`
+	// Names maps language codes such as en, ar or ur to the display name
+	Names       map[string]string `json:"names"`
+	Description string            `json:"description,omitempty"`
+	Icon        string            `json:"icon,omitempty"`
+	CoverImage  string            `json:"coverImage,omitempty"`
+	// SortOrder positions the category in listings, lowest first
+	SortOrder int `json:"sortOrder"`
+	// Hidden categories are left out of the public listing
+	Hidden      bool   `json:"hidden"`
+	LastUpdated string `json:"lastUpdated,omitempty"`
+}
+
+// CategoryListing is a category as listed by the API, with its display name in the
+// requested language and the number of videos in it
+type CategoryListing struct {
+	*Category
+	Name       string `json:"name"`
+	VideoCount int    `json:"videoCount"`
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidateSlug checks that slug is made of lowercase letters, digits and single dashes
+func ValidateSlug(slug string) error {
+	if len(slug) > 64 || !slugPattern.MatchString(slug) {
+		return errs.Validation("category slug %q is invalid, use up to 64 lowercase letters, digits and dashes", slug)
+	}
+	return nil
+}
+
+// ValidateCategory validates the category data
+func ValidateCategory(category *Category) error {
+	if err := ValidateSlug(category.Slug); err != nil {
+		return err
+	}
+	if category.Parent != "" {
+		if err := ValidateSlug(category.Parent); err != nil {
+			return err
+		}
+	}
+	if len(category.Names) == 0 {
+		return errs.Validation("names needs at least one display name")
+	}
+	for lang, name := range category.Names {
+		if lang == "" || strings.TrimSpace(name) == "" {
+			return errs.Validation("names must map language codes to non-empty names")
+		}
+	}
+	return nil
+}
+
+// DisplayName returns the name in lang, falling back to the default language and then
+// to the slug
+func (c *Category) DisplayName(lang string) string {
+	if name, ok := c.Names[lang]; ok {
+		return name
+	}
+	if name, ok := c.Names[DefaultLanguage]; ok {
+		return name
+	}
+	return c.Slug
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"net/url"
+	"strings"
+)
+
 type Creator struct {
 	CreatorID        string `json:"creatorId"`
 	Name             string `json:"name"`
@@ -8,3 +13,39 @@ type Creator struct {
 	ProfilePic       string `json:"profilePic"`
 	LastUpdated      string `json:"lastUpdated"`
 }
+
+// CreatorIDFromChannelLink derives a creator ID from a YouTube channel link.
+// It understands /channel/<id>, /c/<name>, /user/<name> and /@handle links and
+// falls back to the last path segment for anything else.
+func CreatorIDFromChannelLink(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return ""
+	}
+
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	if len(segments) == 0 {
+		return ""
+	}
+
+	switch segments[0] {
+	case "channel", "c", "user":
+		if len(segments) > 1 {
+			return segments[1]
+		}
+		return ""
+	}
+	return segments[len(segments)-1]
+}
+
+// CreatorFromDetails builds the creator document from the creator details stored on a video
+func CreatorFromDetails(details CreatorDetails) Creator {
+	return Creator{
+		CreatorID:        details.CreatorID,
+		Name:             details.Name,
+		ChannelLink:      details.ChannelLink,
+		SubscribersCount: details.SubscribersCount,
+		ProfilePic:       details.ProfilePic,
+		LastUpdated:      details.LastUpdated,
+	}
+}
@@ -1,5 +1,12 @@
 package models
 
+import (
+	"net/url"
+	"strings"
+
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
 type VideoResponse struct {
 	Status  bool        `json:"status"`
 	Data    Video       `json:"data"`
@@ -19,6 +26,7 @@ type Video struct {
 	CreatorDetails CreatorDetails `json:"creatorDetails"`
 	LastUpdated    string         `json:"lastUpdated"`
 	Categories     []string       `json:"categories"`
+	RefreshedAt    string         `json:"refreshedAt,omitempty"`
 }
 
 type Thumbnail struct {
@@ -28,9 +36,51 @@ type Thumbnail struct {
 }
 
 type CreatorDetails struct {
+	CreatorID        string `json:"creatorId,omitempty"`
 	Name             string `json:"name"`
 	ChannelLink      string `json:"channerlLink"`
 	SubscribersCount int    `json:"subscribersCount"`
 	ProfilePic       string `json:"profilePic"`
 	LastUpdated      string `json:"lastUpdated"`
 }
+
+// VideoIDFromURL extracts the video ID from a YouTube watch, youtu.be, shorts, live or
+// embed URL. Anything that is not a URL is returned unchanged as a bare video ID.
+func VideoIDFromURL(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "/") {
+		return value
+	}
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+	if id := u.Query().Get("v"); id != "" {
+		return id
+	}
+
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	switch {
+	case strings.HasSuffix(u.Hostname(), "youtu.be") && len(segments) > 0:
+		return segments[0]
+	case len(segments) > 1 && (segments[0] == "shorts" || segments[0] == "embed" || segments[0] == "live"):
+		return segments[1]
+	}
+	return ""
+}
+
+// ValidateVideo validates the video data
+func ValidateVideo(video *Video) error {
+	// Check if video title is empty
+	if video.Title == "" {
+		return errs.Validation("title is required")
+	}
+
+	// Additional validation rules can be added here
+
+	return nil
+}
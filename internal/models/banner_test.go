@@ -0,0 +1,125 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBannerWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		banner     Banner
+		wantStarts time.Time
+		wantEnds   time.Time
+		wantErr    bool
+	}{
+		{name: "open", banner: Banner{}},
+		{name: "dates", banner: Banner{StartsAt: "2024-03-10", EndsAt: "2024-04-10"}, wantStarts: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), wantEnds: time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC)},
+		{name: "timestamps", banner: Banner{StartsAt: "2024-03-10T18:00:00+03:00"}, wantStarts: time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)},
+		{name: "malformed start", banner: Banner{StartsAt: "10/03/2024"}, wantErr: true},
+		{name: "malformed end", banner: Banner{EndsAt: "tomorrow"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			starts, ends, err := tt.banner.Window()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Window succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !starts.Equal(tt.wantStarts) || !ends.Equal(tt.wantEnds) {
+				t.Errorf("Window = %v, %v, want %v, %v", starts, ends, tt.wantStarts, tt.wantEnds)
+			}
+		})
+	}
+}
+
+func TestBannerActiveAt(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		banner Banner
+		locale string
+		want   bool
+	}{
+		{name: "unscheduled", banner: Banner{Enabled: true}, locale: "en", want: true},
+		{name: "disabled", banner: Banner{}, locale: "en"},
+		{name: "started", banner: Banner{Enabled: true, StartsAt: "2024-03-10T12:00:00Z"}, locale: "en", want: true},
+		{name: "not started", banner: Banner{Enabled: true, StartsAt: "2024-03-10T12:00:01Z"}, locale: "en"},
+		{name: "start date in a later time zone", banner: Banner{Enabled: true, StartsAt: "2024-03-10T14:00:00+03:00"}, locale: "en", want: true},
+		{name: "ending", banner: Banner{Enabled: true, EndsAt: "2024-03-10T12:00:01Z"}, locale: "en", want: true},
+		{name: "end excluded", banner: Banner{Enabled: true, EndsAt: "2024-03-10T12:00:00Z"}, locale: "en"},
+		{name: "end date", banner: Banner{Enabled: true, EndsAt: "2024-03-10"}, locale: "en"},
+		{name: "within the window", banner: Banner{Enabled: true, StartsAt: "2024-03-01", EndsAt: "2024-03-11"}, locale: "en", want: true},
+		{name: "malformed window", banner: Banner{Enabled: true, StartsAt: "soon"}, locale: "en"},
+		{name: "target locale", banner: Banner{Enabled: true, Locales: []string{"ar", "ur"}}, locale: "ur", want: true},
+		{name: "other locale", banner: Banner{Enabled: true, Locales: []string{"ar", "ur"}}, locale: "en"},
+		{name: "regional variant", banner: Banner{Enabled: true, Locales: []string{"ar"}}, locale: "ar-SA", want: true},
+		{name: "regional target", banner: Banner{Enabled: true, Locales: []string{"ar-sa"}}, locale: "ar"},
+		{name: "locale case", banner: Banner{Enabled: true, Locales: []string{"ar-sa"}}, locale: "ar-SA", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.banner.ActiveAt(now, tt.locale); got != tt.want {
+				t.Errorf("ActiveAt(%s) = %t, want %t", tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBanner(t *testing.T) {
+	tests := []struct {
+		name        string
+		banner      Banner
+		wantErr     bool
+		wantLocales []string
+	}{
+		{name: "video", banner: Banner{VideoID: "v1"}},
+		{name: "image", banner: Banner{ImageURL: "https://example.com/eid.png", LinkURL: "/categories/eid"}},
+		{name: "neither video nor image", banner: Banner{Title: "Eid"}, wantErr: true},
+		{name: "image without scheme", banner: Banner{ImageURL: "example.com/eid.png"}, wantErr: true},
+		{name: "link without scheme", banner: Banner{VideoID: "v1", LinkURL: "example.com"}, wantErr: true},
+		{name: "negative position", banner: Banner{VideoID: "v1", Position: -1}, wantErr: true},
+		{name: "empty window", banner: Banner{VideoID: "v1", StartsAt: "2024-03-10", EndsAt: "2024-03-10"}, wantErr: true},
+		{name: "reversed window", banner: Banner{VideoID: "v1", StartsAt: "2024-03-11", EndsAt: "2024-03-10"}, wantErr: true},
+		{name: "locales lowercased", banner: Banner{VideoID: "v1", Locales: []string{"AR-SA", " en "}}, wantLocales: []string{"ar-sa", "en"}},
+		{name: "empty locale", banner: Banner{VideoID: "v1", Locales: []string{"en", " "}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBanner(&tt.banner)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ValidateBanner = %v, want error %t", err, tt.wantErr)
+			}
+			if tt.wantLocales != nil && !reflect.DeepEqual(tt.banner.Locales, tt.wantLocales) {
+				t.Errorf("locales = %v, want %v", tt.banner.Locales, tt.wantLocales)
+			}
+		})
+	}
+}
+
+func TestBannerEnabledByDefault(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{`{"id":"b1","videoId":"v1"}`, true},
+		{`{"id":"b1","videoId":"v1","enabled":true}`, true},
+		{`{"id":"b1","videoId":"v1","enabled":false}`, false},
+	}
+	for _, tt := range tests {
+		var banner Banner
+		if err := json.Unmarshal([]byte(tt.data), &banner); err != nil {
+			t.Fatal(err)
+		}
+		if banner.Enabled != tt.want || banner.VideoID != "v1" {
+			t.Errorf("%s decoded to %+v, want enabled %t", tt.data, banner, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package models
+
+import "github.com/shaik80/ODIW/internal/errs"
+
+// CategoryChange edits the categories of one video. Replace, when not nil, becomes the new
+// category list and an empty list clears it. Otherwise Remove is applied and then the
+// missing categories of Add are appended.
+type CategoryChange struct {
+	Add     []string `json:"add"`
+	Remove  []string `json:"remove"`
+	Replace []string `json:"replace"`
+}
+
+// ValidateCategoryChange checks that the change edits something, that replace is not
+// combined with add or remove and that no category is both added and removed
+func ValidateCategoryChange(change CategoryChange) error {
+	if change.Replace != nil {
+		if len(change.Add) > 0 || len(change.Remove) > 0 {
+			return errs.Validation("replace cannot be combined with add or remove")
+		}
+		return nil
+	}
+	if len(change.Add) == 0 && len(change.Remove) == 0 {
+		return errs.Validation("add, remove or replace is required")
+	}
+	return ValidateCategoryUpdate(CategoryUpdate{Add: change.Add, Remove: change.Remove})
+}
+
+// Categories returns the categories that apply after the change, keeping the order of the
+// current ones and never adding a category twice
+func (change CategoryChange) Categories(current []string) []string {
+	if change.Replace != nil {
+		return appendMissing([]string{}, change.Replace)
+	}
+
+	removed := map[string]bool{}
+	for _, slug := range change.Remove {
+		removed[slug] = true
+	}
+	kept := []string{}
+	for _, category := range current {
+		if !removed[category] {
+			kept = append(kept, category)
+		}
+	}
+	return appendMissing(kept, change.Add)
+}
+
+// EqualCategories reports whether two category lists hold the same slugs in the same order
+func EqualCategories(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// appendMissing appends the values that are not in list yet
+func appendMissing(list []string, values []string) []string {
+	seen := map[string]bool{}
+	for _, value := range list {
+		seen[value] = true
+	}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+func TestCategoryChangeCategories(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  CategoryChange
+		current []string
+		want    []string
+	}{
+		{name: "add", change: CategoryChange{Add: []string{"salah"}}, current: []string{"fiqh"}, want: []string{"fiqh", "salah"}},
+		{name: "add to none", change: CategoryChange{Add: []string{"salah"}}, want: []string{"salah"}},
+		{name: "add present", change: CategoryChange{Add: []string{"fiqh"}}, current: []string{"fiqh", "wudu"}, want: []string{"fiqh", "wudu"}},
+		{name: "add twice", change: CategoryChange{Add: []string{"salah", "salah"}}, want: []string{"salah"}},
+		{name: "remove keeps the order", change: CategoryChange{Remove: []string{"wudu"}}, current: []string{"seerah", "wudu", "fiqh"}, want: []string{"seerah", "fiqh"}},
+		{name: "remove missing", change: CategoryChange{Remove: []string{"salah"}}, current: []string{"fiqh"}, want: []string{"fiqh"}},
+		{name: "remove last", change: CategoryChange{Remove: []string{"fiqh"}}, current: []string{"fiqh"}, want: []string{}},
+		{name: "add and remove", change: CategoryChange{Add: []string{"tahara"}, Remove: []string{"wudu"}}, current: []string{"fiqh", "wudu"}, want: []string{"fiqh", "tahara"}},
+		{name: "replace", change: CategoryChange{Replace: []string{"seerah", "fiqh", "seerah"}}, current: []string{"wudu"}, want: []string{"seerah", "fiqh"}},
+		{name: "replace with none", change: CategoryChange{Replace: []string{}}, current: []string{"wudu"}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.change.Categories(tt.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Categories(%v) = %#v, want %#v", tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCategoryChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  CategoryChange
+		wantErr bool
+	}{
+		{name: "add", change: CategoryChange{Add: []string{"fiqh"}}},
+		{name: "remove", change: CategoryChange{Remove: []string{"fiqh"}}},
+		{name: "replace", change: CategoryChange{Replace: []string{"fiqh"}}},
+		{name: "replace with none", change: CategoryChange{Replace: []string{}}},
+		{name: "nothing", change: CategoryChange{}, wantErr: true},
+		{name: "replace and add", change: CategoryChange{Replace: []string{"fiqh"}, Add: []string{"wudu"}}, wantErr: true},
+		{name: "added and removed", change: CategoryChange{Add: []string{"fiqh"}, Remove: []string{"fiqh"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCategoryChange(tt.change)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ValidateCategoryChange = %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errs.ErrValidation) {
+				t.Errorf("error %v is not a validation error", err)
+			}
+		})
+	}
+}
+
+func TestEqualCategories(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want bool
+	}{
+		{nil, nil, true},
+		{nil, []string{}, true},
+		{[]string{"fiqh", "wudu"}, []string{"fiqh", "wudu"}, true},
+		{[]string{"fiqh", "wudu"}, []string{"wudu", "fiqh"}, false},
+		{[]string{"fiqh"}, []string{"fiqh", "wudu"}, false},
+	}
+	for _, tt := range tests {
+		if got := EqualCategories(tt.a, tt.b); got != tt.want {
+			t.Errorf("EqualCategories(%v, %v) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import "github.com/shaik80/ODIW/internal/errs"
+
+// CategoryUpdate removes and adds categories on every video matching Search. Only the
+// query, filters and text match of the search are used.
+type CategoryUpdate struct {
+	Search VideoSearch
+	Add    []string
+	Remove []string
+}
+
+// CategoryTask is the progress of a category update running on the server. The counts
+// grow while the task runs and are final once Completed is set.
+type CategoryTask struct {
+	ID        string `json:"id"`
+	Completed bool   `json:"completed"`
+	// Total is the number of matching videos, Updated the ones changed and Noops the ones
+	// that already had the requested categories
+	Total   int `json:"total"`
+	Updated int `json:"updated"`
+	Noops   int `json:"noops"`
+	// VersionConflicts counts the videos skipped because they changed while the task ran
+	VersionConflicts int `json:"versionConflicts"`
+	// Failures describes the videos that could not be updated
+	Failures []string `json:"failures,omitempty"`
+	// Error is set when the task itself failed
+	Error string `json:"error,omitempty"`
+}
+
+// ValidateCategoryUpdate checks that the update changes something and that no category is
+// both added and removed
+func ValidateCategoryUpdate(update CategoryUpdate) error {
+	if len(update.Add) == 0 && len(update.Remove) == 0 {
+		return errs.Validation("add or remove needs at least one category")
+	}
+	for _, slug := range update.Remove {
+		for _, added := range update.Add {
+			if slug == added {
+				return errs.Validation("category %s cannot be both added and removed", slug)
+			}
+		}
+	}
+	return update.Search.Filters.Validate()
+}
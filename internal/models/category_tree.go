@@ -0,0 +1,90 @@
+package models
+
+import "github.com/shaik80/ODIW/internal/errs"
+
+// CategoryNode is a category in the category tree. VideoCount counts the videos tagged
+// with the category itself, TotalVideoCount the videos tagged with it or any descendant.
+type CategoryNode struct {
+	CategoryListing
+	TotalVideoCount int             `json:"totalVideoCount"`
+	Children        []*CategoryNode `json:"children"`
+}
+
+// Subtree returns slug followed by the slugs of all its descendants, parents before
+// their children
+func Subtree(categories []*Category, slug string) []string {
+	children := childrenOf(categories)
+	subtree := []string{slug}
+	seen := map[string]bool{slug: true}
+	for i := 0; i < len(subtree); i++ {
+		for _, child := range children[subtree[i]] {
+			// Guards against a cycle stored before the checks existed
+			if !seen[child.Slug] {
+				seen[child.Slug] = true
+				subtree = append(subtree, child.Slug)
+			}
+		}
+	}
+	return subtree
+}
+
+// CheckParent verifies that the category slug can be placed under parent: the parent must
+// exist and must not be the category itself or one of its descendants
+func CheckParent(categories []*Category, slug, parent string) error {
+	if parent == "" {
+		return nil
+	}
+	if parent == slug {
+		return errs.Validation("category %s cannot be its own parent", slug)
+	}
+
+	bySlug := make(map[string]*Category, len(categories))
+	for _, category := range categories {
+		bySlug[category.Slug] = category
+	}
+	if _, ok := bySlug[parent]; !ok {
+		return errs.Validation("parent category %s does not exist", parent)
+	}
+	for ancestor, depth := parent, 0; ancestor != "" && depth <= len(categories); depth++ {
+		if ancestor == slug {
+			return errs.Validation("moving %s under %s would create a cycle", slug, parent)
+		}
+		next, ok := bySlug[ancestor]
+		if !ok {
+			break
+		}
+		ancestor = next.Parent
+	}
+	return nil
+}
+
+// BuildCategoryTree arranges the listings by parent. Listings whose parent is not listed,
+// for example because it is hidden, are left out together with their descendants.
+// Children keep the order of the listings.
+func BuildCategoryTree(listings []CategoryListing) []*CategoryNode {
+	nodes := make(map[string]*CategoryNode, len(listings))
+	for _, listing := range listings {
+		nodes[listing.Slug] = &CategoryNode{CategoryListing: listing, Children: []*CategoryNode{}}
+	}
+
+	roots := []*CategoryNode{}
+	for _, listing := range listings {
+		node := nodes[listing.Slug]
+		if listing.Parent == "" {
+			roots = append(roots, node)
+		} else if parent, ok := nodes[listing.Parent]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	return roots
+}
+
+func childrenOf(categories []*Category) map[string][]*Category {
+	children := map[string][]*Category{}
+	for _, category := range categories {
+		if category.Parent != "" {
+			children[category.Parent] = append(children[category.Parent], category)
+		}
+	}
+	return children
+}
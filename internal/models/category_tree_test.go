@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+// testCategories is fiqh > tahara > wudu, fiqh > salah and seerah, with a cycle a > b > a
+// stored before the checks existed
+func testCategories() []*Category {
+	return []*Category{
+		{Slug: "fiqh"},
+		{Slug: "tahara", Parent: "fiqh"},
+		{Slug: "wudu", Parent: "tahara"},
+		{Slug: "salah", Parent: "fiqh"},
+		{Slug: "seerah"},
+		{Slug: "a", Parent: "b"},
+		{Slug: "b", Parent: "a"},
+	}
+}
+
+func TestSubtree(t *testing.T) {
+	tests := []struct {
+		slug string
+		want []string
+	}{
+		{"fiqh", []string{"fiqh", "tahara", "salah", "wudu"}},
+		{"tahara", []string{"tahara", "wudu"}},
+		{"wudu", []string{"wudu"}},
+		{"unknown", []string{"unknown"}},
+		{"a", []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			if got := Subtree(testCategories(), tt.slug); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Subtree(%s) = %v, want %v", tt.slug, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckParent(t *testing.T) {
+	tests := []struct {
+		name    string
+		slug    string
+		parent  string
+		wantErr string
+	}{
+		{name: "top level", slug: "wudu", parent: ""},
+		{name: "sibling subtree", slug: "salah", parent: "tahara"},
+		{name: "new category", slug: "zakat", parent: "fiqh"},
+		{name: "under an unrelated root", slug: "fiqh", parent: "seerah"},
+		{name: "itself", slug: "fiqh", parent: "fiqh", wantErr: "cannot be its own parent"},
+		{name: "missing parent", slug: "wudu", parent: "tafsir", wantErr: "does not exist"},
+		{name: "under its child", slug: "fiqh", parent: "tahara", wantErr: "would create a cycle"},
+		{name: "under its grandchild", slug: "fiqh", parent: "wudu", wantErr: "would create a cycle"},
+		{name: "under a stored cycle", slug: "seerah", parent: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckParent(testCategories(), tt.slug, tt.parent)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, errs.ErrValidation) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckParent = %v, want a validation error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	var listings []CategoryListing
+	for _, category := range testCategories()[:5] {
+		// tahara is hidden, so wudu goes with it
+		if category.Slug != "tahara" {
+			listings = append(listings, CategoryListing{Category: category, Name: category.Slug})
+		}
+	}
+
+	var render func(nodes []*CategoryNode) string
+	render = func(nodes []*CategoryNode) string {
+		parts := make([]string, len(nodes))
+		for i, node := range nodes {
+			parts[i] = node.Slug
+			if len(node.Children) > 0 {
+				parts[i] += "(" + render(node.Children) + ")"
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+	if got, want := render(BuildCategoryTree(listings)), "fiqh(salah) seerah"; got != want {
+		t.Errorf("tree = %s, want %s", got, want)
+	}
+}
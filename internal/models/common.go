@@ -1,7 +1,20 @@
 package models
 
-type SearchVideosRequest struct {
-	Query string `json:"query" validate:"required"`
-	Page  int    `json:"page" validate:"required,min=1"`
-	Size  int    `json:"size" validate:"required,min=1"`
+// BulkItemResult is the outcome of writing a single document in a bulk request
+type BulkItemResult struct {
+	ID     string `json:"id"`
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// CategoryCount is the number of videos in a category
+type CategoryCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// VideoStats summarises the stored videos
+type VideoStats struct {
+	Total      int             `json:"total"`
+	Categories []CategoryCount `json:"categories"`
 }
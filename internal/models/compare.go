@@ -0,0 +1,97 @@
+package models
+
+import (
+	"context"
+
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// CompareAndUpdate checks if the fields of the current video are different from the provided video
+// and updates the current video with the new values if necessary. The changed fields are
+// logged at debug level.
+func CompareAndUpdate(ctx context.Context, oldVideo, newVideo *Video) (bool, *Video) {
+	var changed []string
+
+	if oldVideo.VideoID != newVideo.VideoID {
+		oldVideo.VideoID = newVideo.VideoID
+		changed = append(changed, "videoId")
+	}
+	if oldVideo.Title != newVideo.Title {
+		oldVideo.Title = newVideo.Title
+		changed = append(changed, "title")
+	}
+	// Compare other fields similarly
+	if !compareThumbnails(oldVideo.Thumbnails, newVideo.Thumbnails) {
+		oldVideo.Thumbnails = newVideo.Thumbnails
+		changed = append(changed, "thumbnails")
+	}
+	if !equalCounts(oldVideo.Likes, newVideo.Likes) {
+		oldVideo.Likes = newVideo.Likes
+		changed = append(changed, "likes")
+	}
+	if oldVideo.ViewsCount != newVideo.ViewsCount {
+		oldVideo.ViewsCount = newVideo.ViewsCount
+		changed = append(changed, "viewsCount")
+	}
+	// Add comparisons for other fields as needed
+	if oldVideo.UploadDate != newVideo.UploadDate {
+		oldVideo.UploadDate = newVideo.UploadDate
+		changed = append(changed, "uploadDate")
+	}
+	if oldVideo.VideoCategory != newVideo.VideoCategory {
+		oldVideo.VideoCategory = newVideo.VideoCategory
+		changed = append(changed, "videoCategory")
+	}
+	if oldVideo.Description != newVideo.Description {
+		oldVideo.Description = newVideo.Description
+		changed = append(changed, "description")
+	}
+	if !equalCounts(oldVideo.Dislikes, newVideo.Dislikes) {
+		oldVideo.Dislikes = newVideo.Dislikes
+		changed = append(changed, "dislikes")
+	}
+	if oldVideo.IsShort != newVideo.IsShort {
+		oldVideo.IsShort = newVideo.IsShort
+		changed = append(changed, "isShort")
+	}
+	if oldVideo.CreatorDetails != newVideo.CreatorDetails {
+		oldVideo.CreatorDetails = newVideo.CreatorDetails
+		changed = append(changed, "creatorDetails")
+	}
+	// A nil category list, as on videos fetched from the metadata provider, keeps the current
+	// categories; an empty list clears them
+	if newVideo.Categories != nil && !EqualCategories(oldVideo.Categories, newVideo.Categories) {
+		oldVideo.Categories = newVideo.Categories
+		changed = append(changed, "categories")
+	}
+	if oldVideo.LastUpdated != newVideo.LastUpdated {
+		oldVideo.LastUpdated = newVideo.LastUpdated
+		changed = append(changed, "lastUpdated")
+	}
+
+	if len(changed) > 0 {
+		lp.FromContext(ctx).Debug("video metadata changed", "video_id", oldVideo.VideoID, "fields", changed)
+	}
+	return len(changed) > 0, oldVideo
+}
+
+// compareThumbnails compares two slices of Thumbnail and returns true if they are equal, false otherwise.
+func compareThumbnails(a, b []Thumbnail) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equalCounts compares two optional counts by value
+func equalCounts(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCompareAndUpdate(t *testing.T) {
+	count := func(n int) *int { return &n }
+	stored := func() *Video {
+		return &Video{
+			VideoID:        "v1",
+			Title:          "How to perform wudu",
+			Thumbnails:     []Thumbnail{{URL: "https://i.ytimg.com/vi/v1/default.jpg", Width: 120, Height: 90}},
+			Likes:          count(10),
+			ViewsCount:     "100",
+			UploadDate:     "2024-01-10",
+			CreatorDetails: CreatorDetails{CreatorID: "@alpha", Name: "Alpha"},
+			Categories:     []string{"fiqh", "wudu"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		update func(*Video)
+		want   bool
+		check  func(*Video) bool
+	}{
+		{name: "unchanged", update: func(v *Video) {}},
+		{name: "same counts at other addresses", update: func(v *Video) { v.Likes = count(10) }},
+		{name: "title", update: func(v *Video) { v.Title = "Wudu" }, want: true, check: func(v *Video) bool { return v.Title == "Wudu" }},
+		{name: "likes", update: func(v *Video) { v.Likes = count(11) }, want: true, check: func(v *Video) bool { return *v.Likes == 11 }},
+		{name: "likes hidden", update: func(v *Video) { v.Likes = nil }, want: true, check: func(v *Video) bool { return v.Likes == nil }},
+		{name: "thumbnails", update: func(v *Video) { v.Thumbnails = nil }, want: true, check: func(v *Video) bool { return len(v.Thumbnails) == 0 }},
+		{name: "creator", update: func(v *Video) { v.CreatorDetails.SubscribersCount = 5 }, want: true, check: func(v *Video) bool { return v.CreatorDetails.SubscribersCount == 5 }},
+		{
+			name:   "nil categories keep the current ones",
+			update: func(v *Video) { v.Categories = nil },
+			check:  func(v *Video) bool { return reflect.DeepEqual(v.Categories, []string{"fiqh", "wudu"}) },
+		},
+		{
+			name:   "empty categories clear them",
+			update: func(v *Video) { v.Categories = []string{} },
+			want:   true,
+			check:  func(v *Video) bool { return v.Categories != nil && len(v.Categories) == 0 },
+		},
+		{
+			name:   "reordered categories",
+			update: func(v *Video) { v.Categories = []string{"wudu", "fiqh"} },
+			want:   true,
+			check:  func(v *Video) bool { return reflect.DeepEqual(v.Categories, []string{"wudu", "fiqh"}) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched := stored()
+			tt.update(fetched)
+			current := stored()
+			changed, updated := CompareAndUpdate(context.Background(), current, fetched)
+			if changed != tt.want {
+				t.Errorf("changed = %t, want %t", changed, tt.want)
+			}
+			if updated != current {
+				t.Error("CompareAndUpdate did not update the current video in place")
+			}
+			if tt.check != nil && !tt.check(updated) {
+				t.Errorf("unexpected video after the update: %+v", updated)
+			}
+		})
+	}
+}
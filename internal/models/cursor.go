@@ -0,0 +1,64 @@
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+// Page selects the hits to return: Size hits from offset From, or the Size hits following
+// Cursor when it is set
+type Page struct {
+	From   int
+	Size   int
+	Cursor *Cursor
+	// PointInTime opens a point in time so that the following pages read the same
+	// documents. A cursor keeps the point in time it was created with.
+	PointInTime bool
+	// KeepAlive is how long the point in time stays open until the next page
+	KeepAlive time.Duration
+}
+
+// Cursor is the position after the last hit of a page. Clients get it as an opaque token
+// and send it back to read the next page.
+type Cursor struct {
+	// After holds the sort values of the last hit, passed to search_after
+	After []interface{} `json:"a"`
+	// Sort is the sort order the values belong to
+	Sort string `json:"s,omitempty"`
+	// PointInTime is the ID of the point in time the pages are read from, if any
+	PointInTime string `json:"p,omitempty"`
+}
+
+// Encode returns the token handed to clients
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor decodes a token returned by Encode. Numbers are kept as json.Number so long
+// sort values go back to OpenSearch unchanged.
+func ParseCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errs.Validation("cursor is invalid")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var cursor Cursor
+	if err := decoder.Decode(&cursor); err != nil || len(cursor.After) == 0 {
+		return nil, errs.Validation("cursor is invalid")
+	}
+	return &cursor, nil
+}
+
+// VideoPage is one page of videos with the total hit count and the cursor of the next
+// page, nil on the last page
+type VideoPage struct {
+	Total  int
+	Videos []*Video
+	Next   *Cursor
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor Cursor
+		want   []interface{}
+	}{
+		{
+			name:   "long sort values stay exact",
+			cursor: Cursor{After: []interface{}{int64(9007199254740993), "v1"}, Sort: SortNewest},
+			want:   []interface{}{json.Number("9007199254740993"), "v1"},
+		},
+		{
+			name:   "relevance scores",
+			cursor: Cursor{After: []interface{}{1.25, json.Number("1704844800000"), "v2"}, Sort: SortRelevance},
+			want:   []interface{}{json.Number("1.25"), json.Number("1704844800000"), "v2"},
+		},
+		{
+			name:   "missing values and point in time",
+			cursor: Cursor{After: []interface{}{nil, "v3"}, Sort: SortMostViewed, PointInTime: "pit-id"},
+			want:   []interface{}{nil, "v3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseCursor(tt.cursor.Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(parsed.After, tt.want) {
+				t.Errorf("After = %#v, want %#v", parsed.After, tt.want)
+			}
+			if parsed.Sort != tt.cursor.Sort || parsed.PointInTime != tt.cursor.PointInTime {
+				t.Errorf("parsed %+v, want sort %q and point in time %q", parsed, tt.cursor.Sort, tt.cursor.PointInTime)
+			}
+		})
+	}
+}
+
+func TestCursorTokenIsURLSafe(t *testing.T) {
+	token := (&Cursor{After: []interface{}{"??>>", "~~~"}, Sort: SortNewest}).Encode()
+	for _, r := range token {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			t.Fatalf("token %q contains %q", token, r)
+		}
+	}
+}
+
+func TestParseCursorRejectsInvalidTokens(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"not base64", "not a cursor!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"a":[12]}`))},
+		{"not JSON", encode("after=1")},
+		{"no sort values", encode(`{"s":"newest"}`)},
+		{"empty sort values", encode(`{"a":[],"s":"newest"}`)},
+		{"sort values not a list", encode(`{"a":"v1"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := ParseCursor(tt.token)
+			if !errors.Is(err, errs.ErrValidation) {
+				t.Errorf("ParseCursor = %+v, %v, want a validation error", cursor, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shaik80/ODIW/internal/errs"
+)
+
+// Sort orders of a video search
+const (
+	SortRelevance  = "relevance"
+	SortNewest     = "newest"
+	SortMostViewed = "most_viewed"
+	SortMostLiked  = "most_liked"
+)
+
+// SearchVideosRequest is the body of POST /api/youtube/search. The query may be empty
+// when at least one filter is set.
+type SearchVideosRequest struct {
+	Query   string        `json:"query"`
+	Page    int           `json:"page"`
+	Size    int           `json:"size"`
+	Filters SearchFilters `json:"filters"`
+	// Sort is one of relevance (default), newest, most_viewed or most_liked
+	Sort string `json:"sort"`
+	// Cursor continues after the page it was returned with, Page is then ignored
+	Cursor string `json:"cursor"`
+	// PointInTime keeps the pages reached through cursors on the documents as they were
+	// at the first page
+	PointInTime bool `json:"pointInTime"`
+}
+
+// SearchFilters narrows a video search. Unset fields do not filter.
+type SearchFilters struct {
+	// CategoriesAny keeps the videos in at least one of the categories
+	CategoriesAny []string `json:"categoriesAny,omitempty"`
+	// CategoriesAll keeps the videos in every one of the categories
+	CategoriesAll []string `json:"categoriesAll,omitempty"`
+	CreatorID     string   `json:"creatorId,omitempty"`
+	IsShort       *bool    `json:"isShort,omitempty"`
+	// UploadedAfter and UploadedBefore bound the upload date, both inclusive. They take
+	// a date such as 2024-01-31 or an RFC 3339 timestamp.
+	UploadedAfter  string `json:"uploadedAfter,omitempty"`
+	UploadedBefore string `json:"uploadedBefore,omitempty"`
+	MinViews       int    `json:"minViews,omitempty"`
+}
+
+// IsEmpty reports whether no filter is set
+func (f SearchFilters) IsEmpty() bool {
+	return len(f.CategoriesAny) == 0 && len(f.CategoriesAll) == 0 && f.CreatorID == "" &&
+		f.IsShort == nil && f.UploadedAfter == "" && f.UploadedBefore == "" && f.MinViews == 0
+}
+
+// Validate checks the upload date bounds and the minimum views
+func (f SearchFilters) Validate() error {
+	if f.MinViews < 0 {
+		return errs.Validation("filters.minViews must not be negative")
+	}
+	from, until, err := f.UploadBounds()
+	if err != nil {
+		return err
+	}
+	if !from.IsZero() && !until.IsZero() && !from.Before(until) {
+		return errs.Validation("filters.uploadedAfter must not be later than filters.uploadedBefore")
+	}
+	return nil
+}
+
+// UploadBounds returns the upload date window as an inclusive start and an exclusive
+// end. A bare UploadedBefore date covers that whole day. Zero times are unbounded.
+func (f SearchFilters) UploadBounds() (from time.Time, until time.Time, err error) {
+	if f.UploadedAfter != "" {
+		if from, err = ParseDate(f.UploadedAfter); err != nil {
+			return from, until, errs.Validation("filters.uploadedAfter %q is not a date or RFC 3339 timestamp", f.UploadedAfter)
+		}
+	}
+	if f.UploadedBefore != "" {
+		if until, err = ParseDate(f.UploadedBefore); err != nil {
+			return from, until, errs.Validation("filters.uploadedBefore %q is not a date or RFC 3339 timestamp", f.UploadedBefore)
+		}
+		if len(f.UploadedBefore) == len(time.DateOnly) {
+			until = until.AddDate(0, 0, 1)
+		} else {
+			until = until.Add(time.Nanosecond)
+		}
+	}
+	return from, until, nil
+}
+
+// ParseDate parses a date such as 2024-01-31 or an RFC 3339 timestamp, the formats
+// accepted by the date fields of the videos index
+func ParseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// VideoSearch is a validated search passed to the repositories
+type VideoSearch struct {
+	Query   string
+	Filters SearchFilters
+	Sort    string
+	Page    Page
+	Match   TextMatch
+}
+
+// TextMatch tunes how the query terms match the indexed text
+type TextMatch struct {
+	// Fuzziness is the edit distance allowed per term: AUTO, 0, 1 or 2. Empty disables it.
+	Fuzziness string
+	// PrefixLength is the number of leading characters that must match exactly
+	PrefixLength int
+	// PhraseBoost weighs titles containing the query as a phrase; zero disables it
+	PhraseBoost float64
+}
+
+// VideoSearchResult holds one page of hits, the total hit count, the facet counts and the
+// cursor of the next page, nil on the last page
+type VideoSearchResult struct {
+	Total  int
+	Hits   []*VideoHit
+	Facets SearchFacets
+	Next   *Cursor
+}
+
+// VideoHit is a video found by a search together with the fragments of its title and
+// description that matched the query
+type VideoHit struct {
+	*Video
+	// Highlights maps "title" and "description" to HTML escaped fragments with the
+	// matching terms wrapped in <em> tags
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+// SearchFacets counts the matching videos per category and per creator. Each facet
+// ignores its own filter so that the other values stay selectable.
+type SearchFacets struct {
+	Categories []CategoryCount `json:"categories"`
+	Creators   []CreatorCount  `json:"creators"`
+}
+
+// CreatorCount is the number of videos of a creator
+type CreatorCount struct {
+	CreatorID string `json:"creatorId"`
+	Name      string `json:"name"`
+	Count     int    `json:"count"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Suggestion is one completion offered while the user types
+type Suggestion struct {
+	Text      string `json:"text"`
+	VideoID   string `json:"videoId,omitempty"`
+	CreatorID string `json:"creatorId,omitempty"`
+	// Count is the number of videos of a creator or category
+	Count int `json:"count,omitempty"`
+}
+
+// Suggestions groups the completions by what they complete to
+type Suggestions struct {
+	Titles     []Suggestion `json:"titles"`
+	Creators   []Suggestion `json:"creators"`
+	Categories []Suggestion `json:"categories"`
+}
+
+// MatchesPrefix reports whether text completes the typed query: every complete word of
+// the query appears in text and the last, possibly partial, word starts one of its
+// words. Matching ignores case and punctuation, like the bool_prefix query on the
+// search_as_you_type fields.
+func MatchesPrefix(text string, query string) bool {
+	terms := words(query)
+	if len(terms) == 0 {
+		return false
+	}
+	tokens := words(text)
+	has := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		has[token] = true
+	}
+
+	for _, term := range terms[:len(terms)-1] {
+		if !has[term] {
+			return false
+		}
+	}
+	last := terms[len(terms)-1]
+	for _, token := range tokens {
+		if strings.HasPrefix(token, last) {
+			return true
+		}
+	}
+	return false
+}
+
+// words lowercases text and splits it into letter and digit runs
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
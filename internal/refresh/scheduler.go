@@ -0,0 +1,195 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/metadata"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+const defaultInterval = 10 * time.Minute
+
+// Scheduler periodically re-fetches the metadata of the least recently refreshed videos
+// so views, likes and subscriber counts do not drift.
+type Scheduler struct {
+	Metadata metadata.VideoMetadataProvider
+	Videos   repository.VideoRepository
+	Creators repository.CreatorRepository
+	// Interval is the time between two refresh runs
+	Interval time.Duration
+	// BatchSize is the number of videos refreshed per run
+	BatchSize int
+	// Concurrency bounds the number of metadata requests in flight
+	Concurrency int
+	// MinAge skips videos refreshed more recently than this
+	MinAge time.Duration
+
+	mu     sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+// Start runs the scheduler in the background until Stop is called
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	ctx, cancel := context.WithCancel(lp.WithContext(context.Background(), lp.Logs.With("component", "refresh")))
+	s.stop, s.done, s.cancel = stop, done, cancel
+
+	go func() {
+		defer close(done)
+		defer cancel()
+
+		logger := lp.FromContext(ctx)
+
+		interval := s.Interval
+		if interval <= 0 {
+			interval = defaultInterval
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		logger.Info("metadata refresh scheduled", "interval", interval.String(), "batch_size", s.BatchSize)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// Stop signals the scheduler, cancels the requests of a running batch and waits for it
+// to return. It may be called before Start, from another goroutine, and more than once.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	if stop != nil {
+		select {
+		case <-stop:
+		default:
+			close(stop)
+			s.cancel()
+		}
+	}
+	s.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// RunOnce refreshes one batch of stale videos and returns how many were refreshed and failed
+func (s *Scheduler) RunOnce(ctx context.Context) (int, int) {
+	logger := lp.FromContext(ctx)
+
+	videos, err := s.Videos.GetStaleVideos(ctx, time.Now().Add(-s.MinAge), s.BatchSize)
+	if err != nil {
+		logger.Error("listing stale videos failed", "error", err)
+		return 0, 0
+	}
+	if len(videos) == 0 {
+		return 0, 0
+	}
+
+	concurrency := s.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		mu        sync.Mutex
+		refreshed int
+		failed    int
+		wg        sync.WaitGroup
+	)
+	sem := make(chan struct{}, concurrency)
+	for _, video := range videos {
+		// Stop starting new refreshes once the scheduler is stopping
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(video *models.Video) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			ok := s.refreshVideo(ctx, video)
+			mu.Lock()
+			defer mu.Unlock()
+			if ok {
+				refreshed++
+			} else {
+				failed++
+			}
+		}(video)
+	}
+	wg.Wait()
+
+	logger.Info("metadata refresh finished", "refreshed", refreshed, "failed", failed)
+	return refreshed, failed
+}
+
+// refreshVideo updates a single video. The refresh time is recorded even when the fetch
+// fails so a broken video does not block the rest of the queue, unless the fetch was
+// canceled by Stop. Only the metadata is written back: the categories of existing may be
+// stale by then, and a video deleted meanwhile stays deleted.
+func (s *Scheduler) refreshVideo(ctx context.Context, existing *models.Video) bool {
+	logger := lp.FromContext(ctx).With("video_id", existing.VideoID)
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	fetched, err := s.Metadata.FetchVideo(ctx, existing.VideoID)
+	if err == nil {
+		err = models.ValidateVideo(fetched)
+	}
+	if errors.Is(err, errs.ErrCanceled) {
+		logger.Info("video refresh canceled")
+		return false
+	}
+	if err != nil {
+		logger.Warn("fetching video metadata failed", "error", err)
+		existing.RefreshedAt = now
+		if err := s.Videos.UpdateVideoMetadata(ctx, existing); err != nil && !errors.Is(err, errs.ErrNotFound) {
+			logger.Error("recording video refresh failed", "error", err)
+		}
+		return false
+	}
+
+	fetched.VideoID = existing.VideoID
+	if fetched.CreatorDetails.CreatorID == "" {
+		fetched.CreatorDetails.CreatorID = models.CreatorIDFromChannelLink(fetched.CreatorDetails.ChannelLink)
+	}
+	_, updated := models.CompareAndUpdate(ctx, existing, fetched)
+	updated.RefreshedAt = now
+	err = s.Videos.UpdateVideoMetadata(ctx, updated)
+	if errors.Is(err, errs.ErrNotFound) {
+		logger.Info("video was deleted during its refresh")
+		return false
+	}
+	if err != nil {
+		logger.Error("updating video failed", "error", err)
+		return false
+	}
+
+	if updated.CreatorDetails.CreatorID != "" {
+		creator := models.CreatorFromDetails(updated.CreatorDetails)
+		if err := s.Creators.InsertOrUpdateCreator(ctx, &creator); err != nil {
+			logger.Warn("updating creator failed", "creator_id", creator.CreatorID, "error", err)
+		}
+	}
+	return true
+}
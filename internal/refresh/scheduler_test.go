@@ -0,0 +1,68 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// racingProvider returns fresh metadata after running edit, which stands for a change
+// made to the video while its metadata is being fetched
+type racingProvider struct {
+	edit func(ctx context.Context, videoID string)
+}
+
+func (p racingProvider) FetchVideo(ctx context.Context, videoID string) (*models.Video, error) {
+	p.edit(ctx, videoID)
+	return &models.Video{VideoID: videoID, Title: "Refreshed " + videoID, ViewsCount: "2000"}, nil
+}
+
+func TestRefreshKeepsConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	videos := repository.NewMemoryVideoRepository()
+	if _, err := videos.BulkIndexVideos(ctx, []*models.Video{
+		{VideoID: "v1", Title: "How to perform wudu", ViewsCount: "1000", Categories: []string{"wudu"}},
+		{VideoID: "v2", Title: "Salah for beginners", ViewsCount: "1000", Categories: []string{"salah"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Scheduler{
+		Metadata: racingProvider{edit: func(ctx context.Context, videoID string) {
+			var err error
+			if videoID == "v1" {
+				_, err = videos.ChangeVideoCategories(ctx, videoID, models.CategoryChange{Add: []string{"tahara"}})
+			} else {
+				err = videos.DeleteVideoByID(ctx, videoID)
+			}
+			if err != nil {
+				t.Error(err)
+			}
+		}},
+		Videos:    videos,
+		Creators:  repository.NewMemoryCreatorRepository(videos),
+		BatchSize: 10,
+	}
+	if refreshed, failed := s.RunOnce(ctx); refreshed != 1 || failed != 1 {
+		t.Errorf("RunOnce = %d refreshed, %d failed, want 1 and 1", refreshed, failed)
+	}
+
+	video, err := videos.GetVideoByID(ctx, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if video.Title != "Refreshed v1" || video.ViewsCount != "2000" || video.RefreshedAt == "" {
+		t.Errorf("metadata was not refreshed: %+v", video)
+	}
+	if !reflect.DeepEqual(video.Categories, []string{"wudu", "tahara"}) {
+		t.Errorf("categories = %v, the change made during the refresh was lost", video.Categories)
+	}
+	if _, err := videos.GetVideoByID(ctx, "v2"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("the video deleted during its refresh was written back: %v", err)
+	}
+}
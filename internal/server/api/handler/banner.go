@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// GetBanners returns the carousel: the banners published now for the requested locale in
+// position order, each with the video it refers to
+func (h *Handler) GetBanners(c *fiber.Ctx) error {
+	locale := c.Query("locale", models.DefaultLanguage)
+
+	banners, err := h.Banners.GetActiveBanners(c.UserContext(), time.Now(), locale)
+	if err != nil {
+		return err
+	}
+
+	var videoIDs []string
+	for _, banner := range banners {
+		if banner.VideoID != "" {
+			videoIDs = append(videoIDs, banner.VideoID)
+		}
+	}
+	videos, err := h.Videos.GetVideosByIDs(c.UserContext(), videoIDs)
+	if err != nil {
+		return err
+	}
+
+	slots := []models.BannerSlot{}
+	for _, banner := range banners {
+		slot := models.BannerSlot{Banner: banner}
+		if banner.VideoID != "" {
+			slot.Video = videos[banner.VideoID]
+			if slot.Video == nil {
+				// A slot without its video can still show its own image
+				lp.FromContext(c.UserContext()).Warn("banner refers to a missing video", "banner_id", banner.ID, "video_id", banner.VideoID)
+				if banner.ImageURL == "" {
+					continue
+				}
+			}
+		}
+		slots = append(slots, slot)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"banners": slots})
+}
+
+// GetAllBanners lists every banner, scheduled and expired ones included
+func (h *Handler) GetAllBanners(c *fiber.Ctx) error {
+	banners, err := h.Banners.GetBanners(c.UserContext())
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"banners": banners})
+}
+
+// GetBanner retrieves a banner by its ID
+func (h *Handler) GetBanner(c *fiber.Ctx) error {
+	banner, err := h.Banners.GetBannerByID(c.UserContext(), c.Params("bannerId"))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"banner": banner})
+}
+
+// CreateBanner stores a new banner from the request body under a generated ID
+func (h *Handler) CreateBanner(c *fiber.Ctx) error {
+	var banner models.Banner
+	if err := c.BodyParser(&banner); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing request body"})
+	}
+	banner.ID = models.NewBannerID()
+	if err := h.validateBanner(c.UserContext(), &banner); err != nil {
+		return err
+	}
+	banner.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+
+	if err := h.Banners.CreateBanner(c.UserContext(), &banner); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"banner": banner})
+}
+
+// UpdateBanner replaces the banner named in the path with the request body
+func (h *Handler) UpdateBanner(c *fiber.Ctx) error {
+	// Fiber reuses the memory behind path parameters, copy the ID since it is stored
+	id := utils.CopyString(c.Params("bannerId"))
+
+	var banner models.Banner
+	if err := c.BodyParser(&banner); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing request body"})
+	}
+	if banner.ID != "" && banner.ID != id {
+		return errs.Validation("id cannot be changed")
+	}
+	banner.ID = id
+	if err := h.validateBanner(c.UserContext(), &banner); err != nil {
+		return err
+	}
+	banner.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+
+	if err := h.Banners.UpdateBanner(c.UserContext(), &banner); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"banner": banner})
+}
+
+// DeleteBanner removes a banner
+func (h *Handler) DeleteBanner(c *fiber.Ctx) error {
+	if err := h.Banners.DeleteBanner(c.UserContext(), c.Params("bannerId")); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "banner deleted successfully"})
+}
+
+// validateBanner validates the banner data and checks that the video it refers to exists
+func (h *Handler) validateBanner(ctx context.Context, banner *models.Banner) error {
+	if err := models.ValidateBanner(banner); err != nil {
+		return err
+	}
+	if banner.VideoID == "" {
+		return nil
+	}
+	_, err := h.Videos.GetVideoByID(ctx, banner.VideoID)
+	if errors.Is(err, errs.ErrNotFound) {
+		return errs.Validation("video %s does not exist", banner.VideoID)
+	}
+	return err
+}
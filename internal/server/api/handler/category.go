@@ -0,0 +1,511 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// GetCategories lists the visible categories in their sort order with their display name
+// in the requested language and their video counts. With tree set the categories are
+// nested under their parents and also carry the video count of their whole subtree.
+func (h *Handler) GetCategories(c *fiber.Ctx) error {
+	lang := c.Query("lang", models.DefaultLanguage)
+
+	categories, err := h.Categories.GetCategories(c.UserContext())
+	if err != nil {
+		return err
+	}
+	visible := make([]*models.Category, 0, len(categories))
+	for _, category := range categories {
+		if !category.Hidden {
+			visible = append(visible, category)
+		}
+	}
+
+	listings, err := h.listCategories(c.UserContext(), visible, lang)
+	if err != nil {
+		return err
+	}
+	if !c.QueryBool("tree") {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"categories": listings})
+	}
+
+	// Roll up over every descendant, hidden ones included, like includeSubcategories does
+	subtrees := make(map[string][]string, len(visible))
+	for _, category := range visible {
+		subtrees[category.Slug] = models.Subtree(categories, category.Slug)
+	}
+	totals, err := h.Videos.CountVideosInSubtrees(c.UserContext(), subtrees)
+	if err != nil {
+		return err
+	}
+
+	tree := models.BuildCategoryTree(listings)
+	var addTotals func(nodes []*models.CategoryNode)
+	addTotals = func(nodes []*models.CategoryNode) {
+		for _, node := range nodes {
+			node.TotalVideoCount = totals[node.Slug]
+			addTotals(node.Children)
+		}
+	}
+	addTotals(tree)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"categories": tree})
+}
+
+// GetCategory retrieves a category by its slug, hidden ones included
+func (h *Handler) GetCategory(c *fiber.Ctx) error {
+	category, err := h.Categories.GetCategoryBySlug(c.UserContext(), c.Params("slug"))
+	if err != nil {
+		return err
+	}
+
+	listings, err := h.listCategories(c.UserContext(), []*models.Category{category}, c.Query("lang", models.DefaultLanguage))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"category": listings[0]})
+}
+
+// CreateCategory stores a new category from the request body
+func (h *Handler) CreateCategory(c *fiber.Ctx) error {
+	var category models.Category
+	if err := c.BodyParser(&category); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing request body"})
+	}
+	if err := models.ValidateCategory(&category); err != nil {
+		return err
+	}
+	if err := h.checkParent(c.UserContext(), category.Slug, category.Parent); err != nil {
+		return err
+	}
+	category.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+
+	if err := h.Categories.CreateCategory(c.UserContext(), &category); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"category": category})
+}
+
+// UpdateCategory replaces the category named in the path with the request body
+func (h *Handler) UpdateCategory(c *fiber.Ctx) error {
+	// Fiber reuses the memory behind path parameters, copy the slug since it is stored
+	slug := utils.CopyString(c.Params("slug"))
+
+	var category models.Category
+	if err := c.BodyParser(&category); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing request body"})
+	}
+	if category.Slug != "" && category.Slug != slug {
+		return errs.Validation("slug cannot be changed, rename the category instead")
+	}
+	category.Slug = slug
+	if err := models.ValidateCategory(&category); err != nil {
+		return err
+	}
+	if err := h.checkParent(c.UserContext(), category.Slug, category.Parent); err != nil {
+		return err
+	}
+	category.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+
+	if err := h.Categories.UpdateCategory(c.UserContext(), &category); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"category": category})
+}
+
+// MoveCategory places a category under another parent, or at the top level when the
+// parent is empty
+func (h *Handler) MoveCategory(c *fiber.Ctx) error {
+	var requestBody struct {
+		Parent string `json:"parent"`
+	}
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing request body"})
+	}
+
+	category, err := h.Categories.GetCategoryBySlug(c.UserContext(), c.Params("slug"))
+	if err != nil {
+		return err
+	}
+	if requestBody.Parent != "" {
+		if err := models.ValidateSlug(requestBody.Parent); err != nil {
+			return err
+		}
+	}
+	if err := h.checkParent(c.UserContext(), category.Slug, requestBody.Parent); err != nil {
+		return err
+	}
+	category.Parent = requestBody.Parent
+	category.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+
+	if err := h.Categories.UpdateCategory(c.UserContext(), category); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"category": category})
+}
+
+// DeleteCategory removes a category that no video and no subcategory uses anymore
+func (h *Handler) DeleteCategory(c *fiber.Ctx) error {
+	slug := c.Params("slug")
+	if _, err := h.Categories.GetCategoryBySlug(c.UserContext(), slug); err != nil {
+		return err
+	}
+
+	categories, err := h.Categories.GetCategories(c.UserContext())
+	if err != nil {
+		return err
+	}
+	if subtree := models.Subtree(categories, slug); len(subtree) > 1 {
+		return errs.Conflict("category %s has subcategories, move or delete them first", slug)
+	}
+
+	counts, err := h.Videos.CountVideosByCategory(c.UserContext(), []string{slug})
+	if err != nil {
+		return err
+	}
+	if counts[slug] > 0 {
+		return errs.Conflict("category %s is used by %d videos", slug, counts[slug])
+	}
+
+	if err := h.Categories.DeleteCategory(c.UserContext(), slug); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "category deleted successfully"})
+}
+
+// listCategories adds the display names and video counts to the categories
+func (h *Handler) listCategories(ctx context.Context, categories []*models.Category, lang string) ([]models.CategoryListing, error) {
+	slugs := make([]string, len(categories))
+	for i, category := range categories {
+		slugs[i] = category.Slug
+	}
+	counts, err := h.Videos.CountVideosByCategory(ctx, slugs)
+	if err != nil {
+		return nil, err
+	}
+
+	listings := make([]models.CategoryListing, len(categories))
+	for i, category := range categories {
+		listings[i] = models.CategoryListing{
+			Category:   category,
+			Name:       category.DisplayName(lang),
+			VideoCount: counts[category.Slug],
+		}
+	}
+	return listings, nil
+}
+
+// checkParent verifies against the stored categories that slug can be placed under parent
+func (h *Handler) checkParent(ctx context.Context, slug, parent string) error {
+	if parent == "" {
+		return nil
+	}
+	categories, err := h.Categories.GetCategories(ctx)
+	if err != nil {
+		return err
+	}
+	return models.CheckParent(categories, slug, parent)
+}
+
+// missingCategories returns the slugs that have no category yet, rejecting malformed ones
+func (h *Handler) missingCategories(ctx context.Context, slugs []string) ([]string, error) {
+	var missing []string
+	for _, slug := range slugs {
+		if err := models.ValidateSlug(slug); err != nil {
+			return nil, err
+		}
+		_, err := h.Categories.GetCategoryBySlug(ctx, slug)
+		if errors.Is(err, errs.ErrNotFound) {
+			missing = append(missing, slug)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
+
+// createCategories creates categories named after their slugs. A category created
+// concurrently by another request is fine.
+func (h *Handler) createCategories(ctx context.Context, slugs []string) error {
+	for _, slug := range slugs {
+		category := &models.Category{
+			Slug:        slug,
+			Names:       map[string]string{models.DefaultLanguage: slug},
+			LastUpdated: time.Now().UTC().Format(time.RFC3339),
+		}
+		if err := h.Categories.CreateCategory(ctx, category); err != nil && !errors.Is(err, errs.ErrConflict) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenameCategory moves a category to a new slug. The category and its subcategories are
+// updated at once, the videos by a server side task whose progress GetCategoryTask reports.
+// The old slug is removed once the task has succeeded, see replaceCategory.
+func (h *Handler) RenameCategory(c *fiber.Ctx) error {
+	var requestBody struct {
+		Slug string `json:"slug"`
+	}
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing request body"})
+	}
+	if err := models.ValidateSlug(requestBody.Slug); err != nil {
+		return err
+	}
+
+	category, err := h.Categories.GetCategoryBySlug(c.UserContext(), c.Params("slug"))
+	if err != nil {
+		return err
+	}
+	if requestBody.Slug == category.Slug {
+		return errs.Validation("category %s already has that slug", category.Slug)
+	}
+
+	oldSlug := category.Slug
+	category.Slug = requestBody.Slug
+	category.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+	if err := h.Categories.CreateCategory(c.UserContext(), category); err != nil {
+		return err
+	}
+
+	task, err := h.replaceCategory(c.UserContext(), oldSlug, category.Slug)
+	if task == nil && err != nil {
+		// Nothing has moved yet, drop the new slug so the rename can be retried
+		if err := h.Categories.DeleteCategory(c.UserContext(), category.Slug); err != nil {
+			lp.FromContext(c.UserContext()).Warn("removing the new category slug failed", "slug", category.Slug, "error", err)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"category": category, "task": task})
+}
+
+// MergeCategory folds a category into another one. Its videos and subcategories move to
+// the target and the category is removed once its videos have moved, see replaceCategory.
+func (h *Handler) MergeCategory(c *fiber.Ctx) error {
+	var requestBody struct {
+		Into string `json:"into"`
+	}
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing request body"})
+	}
+	slug := utils.CopyString(c.Params("slug"))
+	if requestBody.Into == "" {
+		return errs.Validation("into is required")
+	}
+	if requestBody.Into == slug {
+		return errs.Validation("category %s cannot be merged into itself", slug)
+	}
+
+	if _, err := h.Categories.GetCategoryBySlug(c.UserContext(), slug); err != nil {
+		return err
+	}
+	target, err := h.Categories.GetCategoryBySlug(c.UserContext(), requestBody.Into)
+	if err != nil {
+		return err
+	}
+	categories, err := h.Categories.GetCategories(c.UserContext())
+	if err != nil {
+		return err
+	}
+	for _, descendant := range models.Subtree(categories, slug) {
+		if descendant == target.Slug {
+			return errs.Validation("category %s cannot be merged into its subcategory %s", slug, target.Slug)
+		}
+	}
+
+	task, err := h.replaceCategory(c.UserContext(), slug, target.Slug)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"category": target, "task": task})
+}
+
+// UpdateVideoCategories adds and removes categories on every video matching a search query
+// and filters. Terms match exactly, without the typo tolerance of the search endpoint.
+func (h *Handler) UpdateVideoCategories(c *fiber.Ctx) error {
+	var requestBody struct {
+		Query                string               `json:"query"`
+		Filters              models.SearchFilters `json:"filters"`
+		Add                  []string             `json:"add"`
+		Remove               []string             `json:"remove"`
+		AutoCreateCategories bool                 `json:"auto_create_categories"`
+	}
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing request body"})
+	}
+	if requestBody.Query == "" && requestBody.Filters.IsEmpty() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "query or filters are required"})
+	}
+
+	update := models.CategoryUpdate{
+		Search: models.VideoSearch{Query: requestBody.Query, Filters: requestBody.Filters},
+		Add:    requestBody.Add,
+		Remove: requestBody.Remove,
+	}
+	if err := models.ValidateCategoryUpdate(update); err != nil {
+		return err
+	}
+
+	missing, err := h.missingCategories(c.UserContext(), requestBody.Add)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 && !requestBody.AutoCreateCategories {
+		return errs.Validation("unknown categories %s, create them first or set auto_create_categories", strings.Join(missing, ", "))
+	}
+	if err := h.createCategories(c.UserContext(), missing); err != nil {
+		return err
+	}
+
+	task, err := h.Videos.UpdateVideoCategories(c.UserContext(), update)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"task": task})
+}
+
+// GetCategoryTask reports the progress of a rename, merge or bulk category update
+func (h *Handler) GetCategoryTask(c *fiber.Ctx) error {
+	task, err := h.Videos.GetCategoryTask(c.UserContext(), c.Params("taskId"))
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"task": task})
+}
+
+// Replacing a category is not atomic. Until the last update task has completed, videos
+// carry either the old or the new category, both categories exist and the old one keeps
+// its videos written meanwhile. A task that fails, or leftovers that survive every
+// attempt, leave both categories in place; merging the old category into the new one
+// again resumes the operation.
+var (
+	// replaceAttempts bounds the update tasks run for one replacement
+	replaceAttempts = 3
+	// replacePollInterval is the delay between two progress checks of an update task
+	replacePollInterval = 2 * time.Second
+	// replaceTimeout bounds the wait for the update tasks of one replacement
+	replaceTimeout = time.Hour
+)
+
+// replaceCategory starts the task that replaces from by to on the videos, points the
+// subcategories of from at to and removes from once no video uses it anymore, see
+// finishReplaceCategory. The task is returned as soon as it has started, together with
+// any error met afterwards.
+func (h *Handler) replaceCategory(ctx context.Context, from, to string) (*models.CategoryTask, error) {
+	task, err := h.Videos.UpdateVideoCategories(ctx, replaceUpdate(from, to))
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := h.Categories.GetCategories(ctx)
+	if err != nil {
+		return task, err
+	}
+	for _, category := range categories {
+		if category.Parent == from {
+			category.Parent = to
+			category.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+			if err := h.Categories.UpdateCategory(ctx, category); err != nil {
+				return task, err
+			}
+		}
+	}
+
+	// Tasks that ran synchronously are finished before answering, the others in the
+	// background since they can outlive the request. Shutting down stops the background
+	// work, which merging again resumes.
+	if task.Completed {
+		h.finishReplaceCategory(ctx, from, to, task)
+	} else {
+		h.goBackground(ctx, func(ctx context.Context) {
+			h.finishReplaceCategory(ctx, from, to, task)
+		})
+	}
+	return task, nil
+}
+
+// finishReplaceCategory waits for the update task, reruns it for the videos skipped on a
+// version conflict or tagged with from meanwhile, and deletes from once no video uses it.
+// Failures are logged and leave from in place.
+func (h *Handler) finishReplaceCategory(ctx context.Context, from, to string, task *models.CategoryTask) {
+	ctx, cancel := context.WithTimeout(ctx, replaceTimeout)
+	defer cancel()
+	logger := lp.FromContext(ctx).With("from", from, "to", to)
+
+	for attempt := 1; ; attempt++ {
+		var err error
+		if task, err = h.waitForCategoryTask(ctx, task); err != nil {
+			logger.Error("category replacement stopped, merge the category again to finish", "task_id", task.ID, "error", err)
+			return
+		}
+		if task.Error != "" || len(task.Failures) > 0 {
+			logger.Error("category update task failed, merge the category again to finish", "task_id", task.ID, "error", task.Error, "failures", len(task.Failures))
+			return
+		}
+
+		counts, err := h.Videos.CountVideosByCategory(ctx, []string{from})
+		if err != nil {
+			logger.Error("category replacement stopped, merge the category again to finish", "task_id", task.ID, "error", err)
+			return
+		}
+		if counts[from] == 0 && task.VersionConflicts == 0 {
+			break
+		}
+		if attempt == replaceAttempts {
+			logger.Warn("videos still use the category after the last attempt, merge the category again to finish", "videos", counts[from], "attempts", attempt)
+			return
+		}
+
+		logger.Info("rerunning category update for the remaining videos", "task_id", task.ID, "videos", counts[from], "version_conflicts", task.VersionConflicts)
+		if task, err = h.Videos.UpdateVideoCategories(ctx, replaceUpdate(from, to)); err != nil {
+			logger.Error("category replacement stopped, merge the category again to finish", "error", err)
+			return
+		}
+	}
+
+	if err := h.Categories.DeleteCategory(ctx, from); err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.Error("deleting the replaced category failed", "error", err)
+		return
+	}
+	logger.Info("replaced category")
+}
+
+// waitForCategoryTask polls the task until it has completed
+func (h *Handler) waitForCategoryTask(ctx context.Context, task *models.CategoryTask) (*models.CategoryTask, error) {
+	ticker := time.NewTicker(replacePollInterval)
+	defer ticker.Stop()
+	for !task.Completed {
+		select {
+		case <-ctx.Done():
+			return task, ctx.Err()
+		case <-ticker.C:
+		}
+		next, err := h.Videos.GetCategoryTask(ctx, task.ID)
+		if err != nil {
+			return task, err
+		}
+		task = next
+	}
+	return task, nil
+}
+
+// replaceUpdate is the update replacing from by to on the videos tagged with from
+func replaceUpdate(from, to string) models.CategoryUpdate {
+	return models.CategoryUpdate{
+		Search: models.VideoSearch{Filters: models.SearchFilters{CategoriesAny: []string{from}}},
+		Add:    []string{to},
+		Remove: []string{from},
+	}
+}
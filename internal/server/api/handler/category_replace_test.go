@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// scriptedVideos answers the first update tasks with the scripted ones, reported by
+// GetCategoryTask once polled, and runs the later ones on the memory repository
+type scriptedVideos struct {
+	*repository.MemoryVideoRepository
+	tasks   []*models.CategoryTask
+	updates int
+}
+
+func (r *scriptedVideos) UpdateVideoCategories(ctx context.Context, update models.CategoryUpdate) (*models.CategoryTask, error) {
+	r.updates++
+	if r.updates <= len(r.tasks) {
+		return &models.CategoryTask{ID: r.tasks[r.updates-1].ID}, nil
+	}
+	return r.MemoryVideoRepository.UpdateVideoCategories(ctx, update)
+}
+
+func (r *scriptedVideos) GetCategoryTask(ctx context.Context, taskID string) (*models.CategoryTask, error) {
+	for _, task := range r.tasks {
+		if task.ID == taskID {
+			return task, nil
+		}
+	}
+	return r.MemoryVideoRepository.GetCategoryTask(ctx, taskID)
+}
+
+func TestFinishReplaceCategory(t *testing.T) {
+	replacePollInterval = time.Millisecond
+	conflicted := &models.CategoryTask{ID: "conflicted", Completed: true, Total: 2, Updated: 1, VersionConflicts: 1}
+
+	tests := []struct {
+		name        string
+		tasks       []*models.CategoryTask
+		wantUpdates int
+		wantDeleted bool
+	}{
+		{name: "succeeded", wantUpdates: 1, wantDeleted: true},
+		{name: "conflicts are retried", tasks: []*models.CategoryTask{conflicted}, wantUpdates: 2, wantDeleted: true},
+		{name: "failed task", tasks: []*models.CategoryTask{{ID: "failed", Completed: true, Failures: []string{"v1: mapper_parsing_exception"}}}, wantUpdates: 1},
+		{name: "task error", tasks: []*models.CategoryTask{{ID: "errored", Completed: true, Error: "node left"}}, wantUpdates: 1},
+		{name: "conflicts on every attempt", tasks: []*models.CategoryTask{conflicted, conflicted, conflicted}, wantUpdates: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			memory := repository.NewMemoryVideoRepository()
+			if _, err := memory.BulkIndexVideos(ctx, []*models.Video{
+				{VideoID: "v1", Categories: []string{"old"}},
+				{VideoID: "v2", Categories: []string{"old", "other"}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			categories := repository.NewMemoryCategoryRepository()
+			for _, slug := range []string{"old", "new"} {
+				if err := categories.CreateCategory(ctx, &models.Category{Slug: slug}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			videos := &scriptedVideos{MemoryVideoRepository: memory, tasks: tt.tasks}
+			h := &Handler{Videos: videos, Categories: categories}
+
+			task, err := videos.UpdateVideoCategories(ctx, replaceUpdate("old", "new"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			h.finishReplaceCategory(ctx, "old", "new", task)
+
+			if videos.updates != tt.wantUpdates {
+				t.Errorf("update tasks = %d, want %d", videos.updates, tt.wantUpdates)
+			}
+			_, err = categories.GetCategoryBySlug(ctx, "old")
+			if deleted := errors.Is(err, errs.ErrNotFound); deleted != tt.wantDeleted {
+				t.Errorf("old category deleted = %t, want %t (err %v)", deleted, tt.wantDeleted, err)
+			}
+			counts, err := memory.CountVideosByCategory(ctx, []string{"old"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantDeleted && counts["old"] != 0 {
+				t.Errorf("%d videos still use the deleted category", counts["old"])
+			}
+		})
+	}
+}
+
+func TestShutdownStopsCategoryReplacement(t *testing.T) {
+	replacePollInterval = time.Millisecond
+	ctx := context.Background()
+	categories := repository.NewMemoryCategoryRepository()
+	for _, slug := range []string{"old", "new"} {
+		if err := categories.CreateCategory(ctx, &models.Category{Slug: slug}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The update task never completes
+	videos := &scriptedVideos{MemoryVideoRepository: repository.NewMemoryVideoRepository(), tasks: []*models.CategoryTask{{ID: "running"}}}
+	h := New(videos, nil, categories, nil, nil)
+
+	task, err := h.replaceCategory(ctx, "old", "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Completed {
+		t.Fatal("task completed, want it running in the background")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		h.Shutdown()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not stop the replacement")
+	}
+	if _, err := categories.GetCategoryBySlug(ctx, "old"); err != nil {
+		t.Errorf("old category removed by a stopped replacement: %v", err)
+	}
+}
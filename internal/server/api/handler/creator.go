@@ -1,19 +1,173 @@
 package handler
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"time"
 
-func InsertOrUpdateCreator(c *fiber.Ctx) error {
-	return nil
+	"github.com/gofiber/fiber/v2"
+	"github.com/shaik80/ODIW/config"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/models"
+)
+
+// InsertOrUpdateCreator stores the creator from the request body in the creators index
+func (h *Handler) InsertOrUpdateCreator(c *fiber.Ctx) error {
+	var creator models.Creator
+	if err := c.BodyParser(&creator); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing request body"})
+	}
+
+	if creator.CreatorID == "" {
+		creator.CreatorID = models.CreatorIDFromChannelLink(creator.ChannelLink)
+	}
+	if creator.CreatorID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "creatorId or channerlLink is required"})
+	}
+	if creator.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name is required"})
+	}
+	if creator.LastUpdated == "" {
+		creator.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	if err := h.Creators.InsertOrUpdateCreator(c.UserContext(), &creator); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "Creator inserted/updated successfully", "creator": creator})
 }
 
-func GetCreator(c *fiber.Ctx) error {
-	return nil
+// GetCreator retrieves a creator by its ID
+func (h *Handler) GetCreator(c *fiber.Ctx) error {
+	creatorID := c.Params("creatorId")
+	if creatorID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "creatorId parameter is required"})
+	}
+
+	creator, err := h.Creators.GetCreatorByID(c.UserContext(), creatorID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"creator": creator})
+}
+
+// GetCreators lists all creators with pagination
+func (h *Handler) GetCreators(c *fiber.Ctx) error {
+	page, size := paginationQuery(c)
+	if err := checkDepth(page, size); err != nil {
+		return err
+	}
+	from := (page - 1) * size
+
+	total, creators, err := h.Creators.GetCreators(c.UserContext(), from, size)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"page":     page,
+		"size":     size,
+		"total":    total,
+		"creators": creators,
+	})
 }
 
-func GetCreators(c *fiber.Ctx) error {
+// GetVideosByCreator retrieves the videos of a creator with pagination
+func (h *Handler) GetVideosByCreator(c *fiber.Ctx) error {
+	creatorID := c.Params("creatorId")
+	if creatorID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "creatorId parameter is required"})
+	}
+
+	creator, err := h.Creators.GetCreatorByID(c.UserContext(), creatorID)
+	if err != nil {
+		return err
+	}
+
+	page, size := paginationQuery(c)
+	if err := checkDepth(page, size); err != nil {
+		return err
+	}
+	from := (page - 1) * size
+
+	total, videos, err := h.Creators.SearchVideosByCreator(c.UserContext(), creator, from, size)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"creator": creator,
+		"page":    page,
+		"size":    size,
+		"total":   total,
+		"videos":  videos,
+	})
+}
+
+// paginationQuery reads the page and size query parameters, falling back to page 1 of 10
+func paginationQuery(c *fiber.Ctx) (int, int) {
+	page := c.QueryInt("page", 1)
+	size := c.QueryInt("size", 10)
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+	return page, size
+}
+
+// checkDepth rejects pages larger than search.max_page_size and pages reaching past
+// search.max_result_window results, which OpenSearch refuses to return with from and size
+func checkDepth(page int, size int) error {
+	if err := checkSize(size); err != nil {
+		return err
+	}
+	if window := config.Cfg.Search.MaxResultWindow; page*size > window {
+		return errs.Validation("page %d of size %d reaches past the first %d results, use cursors to read further", page, size, window)
+	}
 	return nil
 }
 
-func GetVideosByCreator(c *fiber.Ctx) error {
+// checkSize rejects pages larger than search.max_page_size
+func checkSize(size int) error {
+	if max := config.Cfg.Search.MaxPageSize; size > max {
+		return errs.Validation("size %d is larger than the maximum of %d", size, max)
+	}
 	return nil
 }
+
+// selectPage builds the page selection of a request. A cursor replaces the page number and
+// has no depth limit but the same size limit. The point in time is kept alive for search.point_in_time_keep_alive.
+func selectPage(page int, size int, cursor string, pointInTime bool) (models.Page, error) {
+	selection := models.Page{
+		Size:        size,
+		PointInTime: pointInTime,
+		KeepAlive:   config.Cfg.Search.PointInTimeKeepAlive,
+	}
+	if cursor != "" {
+		if err := checkSize(size); err != nil {
+			return selection, err
+		}
+		parsed, err := models.ParseCursor(cursor)
+		if err != nil {
+			return selection, err
+		}
+		selection.Cursor = parsed
+		return selection, nil
+	}
+	if err := checkDepth(page, size); err != nil {
+		return selection, err
+	}
+	selection.From = (page - 1) * size
+	return selection, nil
+}
+
+// nextCursor returns the token of the next page, or nil on the last page
+func nextCursor(next *models.Cursor) *string {
+	if next == nil {
+		return nil
+	}
+	token := next.Encode()
+	return &token
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shaik80/ODIW/internal/errs"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// statusClientClosedRequest is the non-standard status logged for requests the client
+// abandoned. The client never sees it.
+const statusClientClosedRequest = 499
+
+// errorStatuses maps each domain error kind to its HTTP status and error code. Canceled
+// and timed out calls come first since they also carry the kind of the failed call.
+var errorStatuses = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{errs.ErrCanceled, statusClientClosedRequest, "canceled"},
+	{errs.ErrTimeout, fiber.StatusGatewayTimeout, "timeout"},
+	{errs.ErrNotFound, fiber.StatusNotFound, "not_found"},
+	{errs.ErrConflict, fiber.StatusConflict, "conflict"},
+	{errs.ErrValidation, fiber.StatusBadRequest, "validation_failed"},
+	{errs.ErrUpstreamUnavailable, fiber.StatusBadGateway, "upstream_unavailable"},
+	{errs.ErrStorageUnavailable, fiber.StatusServiceUnavailable, "storage_unavailable"},
+	{errs.ErrUnauthenticated, fiber.StatusUnauthorized, "unauthenticated"},
+	{errs.ErrForbidden, fiber.StatusForbidden, "forbidden"},
+}
+
+// ErrorHandler is the Fiber error handler. It maps domain errors returned by handlers
+// to HTTP status codes and renders every error as {"error": message, "code": code}.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	status, code, message := fiber.StatusInternalServerError, "internal_error", "internal server error"
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status, code, message = fiberErr.Code, "http_error", fiberErr.Message
+	} else {
+		for _, mapping := range errorStatuses {
+			if errors.Is(err, mapping.kind) {
+				status, code, message = mapping.status, mapping.code, errs.Message(err, mapping.kind.Error())
+				break
+			}
+		}
+	}
+
+	if status == fiber.StatusUnauthorized {
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="api"`)
+	}
+	if status >= fiber.StatusInternalServerError {
+		lp.FromContext(c.UserContext()).Error("request failed", "method", c.Method(), "path", c.Path(), "status", status, "error", err)
+	}
+
+	return c.Status(status).JSON(fiber.Map{"error": message, "code": code})
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/metadata"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// Handler serves the API routes using the injected repositories
+type Handler struct {
+	Videos     repository.VideoRepository
+	Creators   repository.CreatorRepository
+	Categories repository.CategoryRepository
+	Banners    repository.BannerRepository
+	Metadata   metadata.VideoMetadataProvider
+
+	// background is the context of the work that outlives its request, cancelled by
+	// Shutdown which then waits for running to finish
+	background     context.Context
+	stopBackground context.CancelFunc
+	running        sync.WaitGroup
+}
+
+// New returns a Handler backed by the given repositories and metadata provider
+func New(videos repository.VideoRepository, creators repository.CreatorRepository, categories repository.CategoryRepository, banners repository.BannerRepository, provider metadata.VideoMetadataProvider) *Handler {
+	background, stop := context.WithCancel(context.Background())
+	return &Handler{
+		Videos:         videos,
+		Creators:       creators,
+		Categories:     categories,
+		Banners:        banners,
+		Metadata:       provider,
+		background:     background,
+		stopBackground: stop,
+	}
+}
+
+// Shutdown cancels the work the handlers left running in the background, such as the end
+// of a category replacement, and waits for it to stop
+func (h *Handler) Shutdown() {
+	h.stopBackground()
+	h.running.Wait()
+}
+
+// goBackground runs work in a goroutine tracked by Shutdown. Its context keeps the logger
+// of ctx but not its deadline, and is cancelled by Shutdown instead.
+func (h *Handler) goBackground(ctx context.Context, work func(ctx context.Context)) {
+	h.running.Add(1)
+	go func() {
+		defer h.running.Done()
+		work(lp.WithContext(h.background, lp.FromContext(ctx)))
+	}()
+}
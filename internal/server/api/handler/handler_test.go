@@ -0,0 +1,369 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shaik80/ODIW/config"
+	"github.com/shaik80/ODIW/internal/auth"
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/metadata"
+	"github.com/shaik80/ODIW/internal/models"
+	"github.com/shaik80/ODIW/internal/server/api/handler"
+	"github.com/shaik80/ODIW/internal/server/api/router"
+)
+
+func TestMain(m *testing.M) {
+	// The handlers read the search settings from the global config
+	config.Cfg.Search = config.SearchConfig{Fuzziness: "AUTO", PrefixLength: 1, PhraseBoost: 2, MaxResultWindow: 10000, MaxPageSize: 100}
+	os.Exit(m.Run())
+}
+
+// newTestApp serves the API over memory repositories holding a small category tree
+// and catalogue, with authentication disabled
+func newTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+	return newTestAppWithAuth(t, config.AuthConfig{})
+}
+
+// newTestAppWithAuth is newTestApp with the given authentication settings
+func newTestAppWithAuth(t *testing.T, authConfig config.AuthConfig) *fiber.App {
+	t.Helper()
+	ctx := context.Background()
+
+	videos := repository.NewMemoryVideoRepository()
+	if _, err := videos.BulkIndexVideos(ctx, []*models.Video{
+		{VideoID: "v1", Title: "How to perform wudu", UploadDate: "2024-01-10", Categories: []string{"wudu"}, CreatorDetails: models.CreatorDetails{CreatorID: "@alpha", Name: "Alpha"}},
+		{VideoID: "v2", Title: "Salah for beginners", UploadDate: "2024-03-01", Categories: []string{"fiqh", "salah"}, CreatorDetails: models.CreatorDetails{CreatorID: "@alpha", Name: "Alpha"}},
+		{VideoID: "v3", Title: "Seerah of the Prophet", UploadDate: "2023-11-20", Categories: []string{"seerah"}, CreatorDetails: models.CreatorDetails{CreatorID: "@beta", Name: "Beta"}},
+		{VideoID: "v4", Title: "Wudu mistakes", UploadDate: "2024-02-15", Categories: []string{"wudu"}, CreatorDetails: models.CreatorDetails{CreatorID: "@beta", Name: "Beta"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	categories := repository.NewMemoryCategoryRepository()
+	for _, category := range []*models.Category{
+		{Slug: "fiqh", Names: map[string]string{"en": "Fiqh", "ar": "فقه"}},
+		{Slug: "wudu", Parent: "fiqh", Names: map[string]string{"en": "Wudu"}},
+		{Slug: "salah", Parent: "fiqh", Names: map[string]string{"en": "Salah"}},
+		{Slug: "seerah", Names: map[string]string{"en": "Seerah"}},
+		{Slug: "drafts", Names: map[string]string{"en": "Drafts"}, Hidden: true},
+	} {
+		if err := categories.CreateCategory(ctx, category); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	authn, err := auth.New(authConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := handler.New(videos, repository.NewMemoryCreatorRepository(videos), categories, repository.NewMemoryBannerRepository(), metadata.NewFileProvider("../../../../fixtures/videos"))
+	return router.SetupRoutes(fiber.New(fiber.Config{ErrorHandler: handler.ErrorHandler}), h, authn)
+}
+
+// request sends a JSON request and decodes the JSON response
+func request(t *testing.T, app *fiber.App, method, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+	return requestWithKey(t, app, "", method, path, body)
+}
+
+// requestWithKey is request sending apiKey, if any, in the API key header
+func requestWithKey(t *testing.T, app *fiber.App, apiKey, method, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set(auth.HeaderAPIKey, apiKey)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("%s %s: decoding %q: %s", method, path, data, err)
+	}
+	return resp.StatusCode, decoded
+}
+
+// field walks a decoded JSON document along keys and array indices
+func field(t *testing.T, document interface{}, path ...interface{}) interface{} {
+	t.Helper()
+	for _, step := range path {
+		switch key := step.(type) {
+		case string:
+			object, ok := document.(map[string]interface{})
+			if !ok {
+				t.Fatalf("%v is not an object", document)
+			}
+			document = object[key]
+		case int:
+			array, ok := document.([]interface{})
+			if !ok || key >= len(array) {
+				t.Fatalf("%v has no element %d", document, key)
+			}
+			document = array[key]
+		}
+	}
+	return document
+}
+
+func videoIDs(t *testing.T, body map[string]interface{}) []string {
+	t.Helper()
+	ids := []string{}
+	for _, video := range field(t, body, "videos").([]interface{}) {
+		ids = append(ids, field(t, video, "videoId").(string))
+	}
+	return ids
+}
+
+func TestHandlers(t *testing.T) {
+	cursor := (&models.Cursor{After: []interface{}{1.0, nil, nil, nil, "v1"}, Sort: models.SortRelevance}).Encode()
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		check      func(t *testing.T, body map[string]interface{})
+	}{
+		{
+			name: "get video", method: http.MethodGet, path: "/api/youtube/video/v1", wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				if title := field(t, body, "video", "title"); title != "How to perform wudu" {
+					t.Errorf("title = %v", title)
+				}
+			},
+		},
+		{name: "missing video", method: http.MethodGet, path: "/api/youtube/video/nope", wantStatus: http.StatusNotFound},
+		{
+			name: "search", method: http.MethodPost, path: "/api/youtube/search", body: `{"query":"wudu","sort":"newest"}`, wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				if ids := videoIDs(t, body); !reflect.DeepEqual(ids, []string{"v4", "v1"}) {
+					t.Errorf("videos = %v", ids)
+				}
+			},
+		},
+		{
+			name: "search facets", method: http.MethodPost, path: "/api/youtube/search", body: `{"filters":{"creatorId":"@beta"}}`, wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				if total := field(t, body, "total"); total != 2.0 {
+					t.Errorf("total = %v", total)
+				}
+				if creators := field(t, body, "facets", "creators").([]interface{}); len(creators) != 2 {
+					t.Errorf("creator facet = %v, want both creators", creators)
+				}
+			},
+		},
+		{name: "search without query or filters", method: http.MethodPost, path: "/api/youtube/search", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "search with unknown sort", method: http.MethodPost, path: "/api/youtube/search", body: `{"query":"wudu","sort":"oldest"}`, wantStatus: http.StatusBadRequest},
+		{
+			name: "search cursor", method: http.MethodPost, path: "/api/youtube/search", body: `{"query":"wudu","sort":"newest","size":1}`, wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				if cursor, _ := field(t, body, "nextCursor").(string); cursor == "" {
+					t.Errorf("nextCursor missing from %v", body)
+				}
+			},
+		},
+		{name: "search page over the size limit", method: http.MethodPost, path: "/api/youtube/search", body: `{"query":"wudu","size":101}`, wantStatus: http.StatusBadRequest},
+		{name: "search cursor over the size limit", method: http.MethodPost, path: "/api/youtube/search", body: `{"query":"wudu","size":5000,"cursor":"` + cursor + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "category cursor over the size limit", method: http.MethodGet, path: "/api/youtube/videos/category/fiqh?size=5000&cursor=" + cursor, wantStatus: http.StatusBadRequest},
+		{name: "creators over the size limit", method: http.MethodGet, path: "/api/youtube/creators?size=101", wantStatus: http.StatusBadRequest},
+		{
+			name: "videos by category", method: http.MethodGet, path: "/api/youtube/videos/category/fiqh", wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				if ids := videoIDs(t, body); !reflect.DeepEqual(ids, []string{"v2"}) {
+					t.Errorf("videos = %v", ids)
+				}
+			},
+		},
+		{
+			name: "videos by category with subcategories", method: http.MethodGet, path: "/api/youtube/videos/category/fiqh?includeSubcategories=true", wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				if total := field(t, body, "total"); total != 3.0 {
+					t.Errorf("total = %v, want 3", total)
+				}
+			},
+		},
+		{
+			name: "category list leaves hidden categories out", method: http.MethodGet, path: "/api/youtube/categories?lang=ar", wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				var names []string
+				for _, category := range field(t, body, "categories").([]interface{}) {
+					names = append(names, field(t, category, "name").(string))
+				}
+				if want := []string{"فقه", "Salah", "Seerah", "Wudu"}; !reflect.DeepEqual(names, want) {
+					t.Errorf("names = %v, want %v", names, want)
+				}
+			},
+		},
+		{
+			name: "category tree rolls up counts", method: http.MethodGet, path: "/api/youtube/categories?tree=true", wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				fiqh := field(t, body, "categories", 0)
+				if slug := field(t, fiqh, "slug"); slug != "fiqh" {
+					t.Fatalf("first root = %v", slug)
+				}
+				if count, total := field(t, fiqh, "videoCount"), field(t, fiqh, "totalVideoCount"); count != 1.0 || total != 3.0 {
+					t.Errorf("videoCount = %v, totalVideoCount = %v, want 1 and 3", count, total)
+				}
+				if children := field(t, fiqh, "children").([]interface{}); len(children) != 2 {
+					t.Errorf("children = %v", children)
+				}
+			},
+		},
+		{name: "create category with invalid slug", method: http.MethodPost, path: "/api/youtube/categories", body: `{"slug":"Bad Slug","names":{"en":"Bad"}}`, wantStatus: http.StatusBadRequest},
+		{name: "create existing category", method: http.MethodPost, path: "/api/youtube/categories", body: `{"slug":"fiqh","names":{"en":"Fiqh"}}`, wantStatus: http.StatusConflict},
+		{name: "change slug through update", method: http.MethodPut, path: "/api/youtube/categories/fiqh", body: `{"slug":"law","names":{"en":"Fiqh"}}`, wantStatus: http.StatusBadRequest},
+		{name: "delete category with videos", method: http.MethodDelete, path: "/api/youtube/categories/seerah", wantStatus: http.StatusConflict},
+		{name: "delete category with subcategories", method: http.MethodDelete, path: "/api/youtube/categories/fiqh", wantStatus: http.StatusConflict},
+		{name: "delete unused category", method: http.MethodDelete, path: "/api/youtube/categories/drafts", wantStatus: http.StatusOK},
+		{name: "move category under its descendant", method: http.MethodPut, path: "/api/youtube/categories/fiqh/parent", body: `{"parent":"wudu"}`, wantStatus: http.StatusBadRequest},
+		{
+			name: "patch categories", method: http.MethodPatch, path: "/api/youtube/video/v2/categories", body: `{"add":["seerah"],"remove":["fiqh"]}`, wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				if categories := field(t, body, "categories"); !reflect.DeepEqual(categories, []interface{}{"salah", "seerah"}) {
+					t.Errorf("categories = %v", categories)
+				}
+			},
+		},
+		{name: "patch with unknown category", method: http.MethodPatch, path: "/api/youtube/video/v2/categories", body: `{"add":["tafsir"]}`, wantStatus: http.StatusBadRequest},
+		{name: "patch without changes", method: http.MethodPatch, path: "/api/youtube/video/v2/categories", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "insert video with unknown category", method: http.MethodPost, path: "/api/youtube/video", body: `{"video_id":"sample-video","categories":["tafsir"]}`, wantStatus: http.StatusBadRequest},
+		{name: "insert video creating its categories", method: http.MethodPost, path: "/api/youtube/video", body: `{"video_id":"sample-video","categories":["tafsir"],"auto_create_categories":true}`, wantStatus: http.StatusOK},
+		{
+			name: "rename category", method: http.MethodPost, path: "/api/youtube/categories/wudu/rename", body: `{"slug":"tahara","names":{"en":"Tahara"}}`, wantStatus: http.StatusAccepted,
+			check: func(t *testing.T, body map[string]interface{}) {
+				if updated := field(t, body, "task", "updated"); updated != 2.0 {
+					t.Errorf("task updated %v videos, want 2", updated)
+				}
+			},
+		},
+		{name: "merge into a subcategory", method: http.MethodPost, path: "/api/youtube/categories/fiqh/merge", body: `{"into":"salah"}`, wantStatus: http.StatusBadRequest},
+		{name: "banner without video or image", method: http.MethodPost, path: "/api/youtube/banners", body: `{"title":"Empty"}`, wantStatus: http.StatusBadRequest},
+		{name: "banner for a missing video", method: http.MethodPost, path: "/api/youtube/banners", body: `{"videoId":"nope"}`, wantStatus: http.StatusBadRequest},
+		{name: "create banner", method: http.MethodPost, path: "/api/youtube/banners", body: `{"videoId":"v1","position":1}`, wantStatus: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			status, body := request(t, app, tt.method, tt.path, tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %v", status, tt.wantStatus, body)
+			}
+			if tt.check != nil {
+				tt.check(t, body)
+			}
+		})
+	}
+}
+
+func TestRenameCategoryMovesVideos(t *testing.T) {
+	app := newTestApp(t)
+
+	if status, body := request(t, app, http.MethodPost, "/api/youtube/categories/wudu/rename", `{"slug":"tahara","names":{"en":"Tahara"}}`); status != http.StatusAccepted {
+		t.Fatalf("rename: status %d: %v", status, body)
+	}
+	if status, _ := request(t, app, http.MethodGet, "/api/youtube/categories/wudu", ""); status != http.StatusNotFound {
+		t.Errorf("old category: status %d, want 404", status)
+	}
+	status, body := request(t, app, http.MethodGet, "/api/youtube/categories/tahara", "")
+	if status != http.StatusOK {
+		t.Fatalf("new category: status %d: %v", status, body)
+	}
+	if parent, count := field(t, body, "category", "parent"), field(t, body, "category", "videoCount"); parent != "fiqh" || count != 2.0 {
+		t.Errorf("parent = %v, videoCount = %v, want fiqh and 2", parent, count)
+	}
+}
+
+func TestBannerCarousel(t *testing.T) {
+	app := newTestApp(t)
+
+	for _, banner := range []string{
+		`{"videoId":"v2","position":2}`,
+		`{"imageUrl":"https://example.com/eid.png","position":1,"locales":["ar"]}`,
+		`{"videoId":"v1","position":0,"endsAt":"2000-01-01"}`,
+		`{"videoId":"v3","position":3,"startsAt":"2999-01-01"}`,
+		`{"videoId":"v1","position":4,"enabled":false}`,
+		`{"videoId":"v4","position":5,"locales":["EN"]}`,
+	} {
+		if status, body := request(t, app, http.MethodPost, "/api/youtube/banners", banner); status != http.StatusCreated {
+			t.Fatalf("create %s: status %d: %v", banner, status, body)
+		}
+	}
+
+	tests := []struct {
+		locale string
+		want   []interface{}
+	}{
+		{"en", []interface{}{"v2", "v4"}},
+		{"en-GB", []interface{}{"v2", "v4"}},
+		{"ar-SA", []interface{}{nil, "v2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			status, body := request(t, app, http.MethodGet, "/api/youtube/banner?locale="+tt.locale, "")
+			if status != http.StatusOK {
+				t.Fatalf("status %d: %v", status, body)
+			}
+			var got []interface{}
+			for _, slot := range field(t, body, "banners").([]interface{}) {
+				got = append(got, slot.(map[string]interface{})["videoId"])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("slots = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoutesRequireRoles(t *testing.T) {
+	app := newTestAppWithAuth(t, config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "dashboard", Key: "viewer-key", Role: "viewer"},
+			{Name: "curation-team", Key: "curator-key", Role: "curator"},
+			{Name: "ops", Key: "admin-key", Role: "admin"},
+		},
+	})
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "public read without key", method: http.MethodGet, path: "/api/youtube/categories", wantStatus: http.StatusOK},
+		{name: "curator route without key", method: http.MethodPost, path: "/api/youtube/categories", body: `{"slug":"tahara","names":{"en":"Tahara"}}`, wantStatus: http.StatusUnauthorized},
+		{name: "curator route with wrong key", apiKey: "guess", method: http.MethodPost, path: "/api/youtube/categories", body: `{"slug":"tahara","names":{"en":"Tahara"}}`, wantStatus: http.StatusUnauthorized},
+		{name: "curator route as viewer", apiKey: "viewer-key", method: http.MethodPost, path: "/api/youtube/categories", body: `{"slug":"tahara","names":{"en":"Tahara"}}`, wantStatus: http.StatusForbidden},
+		{name: "curator route as curator", apiKey: "curator-key", method: http.MethodPost, path: "/api/youtube/categories", body: `{"slug":"tahara","names":{"en":"Tahara"}}`, wantStatus: http.StatusCreated},
+		{name: "curator route as admin", apiKey: "admin-key", method: http.MethodPost, path: "/api/youtube/categories", body: `{"slug":"adab","names":{"en":"Adab"}}`, wantStatus: http.StatusCreated},
+		{name: "admin route without key", method: http.MethodDelete, path: "/api/youtube/categories/drafts", wantStatus: http.StatusUnauthorized},
+		{name: "admin route as curator", apiKey: "curator-key", method: http.MethodDelete, path: "/api/youtube/categories/drafts", wantStatus: http.StatusForbidden},
+		{name: "admin route as admin", apiKey: "admin-key", method: http.MethodDelete, path: "/api/youtube/categories/drafts", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := requestWithKey(t, app, tt.apiKey, tt.method, tt.path, tt.body)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %v", status, tt.wantStatus, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"bytes"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shaik80/ODIW/config"
+	"github.com/shaik80/ODIW/internal/errs"
+	"github.com/shaik80/ODIW/internal/importer"
+)
+
+// BulkImportVideos imports the videos listed in a CSV or NDJSON request body.
+// The format is taken from the format query parameter or the Content-Type header.
+// Videos tagged with unknown categories fail unless auto_create_categories is set in the query.
+func (h *Handler) BulkImportVideos(c *fiber.Ctx) error {
+	format := c.Query("format")
+	if format == "" {
+		format = importer.FormatFromContentType(c.Get(fiber.HeaderContentType))
+	}
+
+	items, err := importer.Parse(bytes.NewReader(c.Body()), format)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return errs.Validation("request body contains no videos")
+	}
+	if max := config.Cfg.Import.MaxItems; max > 0 && len(items) > max {
+		return errs.Validation("too many videos: %d, the limit is %d", len(items), max)
+	}
+
+	im := importer.New(h.Metadata, h.Videos, h.Creators, h.Categories)
+	im.AutoCreateCategories = c.QueryBool("auto_create_categories")
+	im.Concurrency = config.Cfg.Import.Concurrency
+	im.BatchSize = config.Cfg.Import.BatchSize
+
+	return c.Status(fiber.StatusOK).JSON(im.Run(c.UserContext(), items))
+}
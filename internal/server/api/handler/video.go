@@ -1,23 +1,26 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+	"strings"
+	"time"
 
-	db "github.com/shaik80/ODIW/internal/db/opensearch/controller"
+	"github.com/shaik80/ODIW/config"
+	"github.com/shaik80/ODIW/internal/errs"
 	"github.com/shaik80/ODIW/internal/models"
+	lp "github.com/shaik80/ODIW/utils/logger"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func InsertOrUpdateVideo(c *fiber.Ctx) error {
+func (h *Handler) InsertOrUpdateVideo(c *fiber.Ctx) error {
 	// Parse request body
 	var requestBody struct {
 		VideoID    string   `json:"video_id"`
 		Categories []string `json:"categories"`
+		// AutoCreateCategories creates the categories that do not exist yet instead of
+		// rejecting the request
+		AutoCreateCategories bool `json:"auto_create_categories"`
 	}
 
 	if err := c.BodyParser(&requestBody); err != nil {
@@ -29,42 +32,63 @@ func InsertOrUpdateVideo(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "video_id parameter is required"})
 	}
 
-	// Send request to fetch video data
-	body, err := NewRequest(videoID)
+	// Check the categories before the metadata is fetched
+	missing, err := h.missingCategories(c.UserContext(), requestBody.Categories)
 	if err != nil {
 		return err
 	}
+	if len(missing) > 0 && !requestBody.AutoCreateCategories {
+		return errs.Validation("unknown categories %s, create them first or set auto_create_categories", strings.Join(missing, ", "))
+	}
 
-	// Parse JSON response
-	var response models.VideoResponse
-	if err := json.Unmarshal(body, &response); err != nil {
+	// Fetch video data from the metadata provider
+	video, err := h.Metadata.FetchVideo(c.UserContext(), videoID)
+	if err != nil {
 		return err
 	}
 
 	// Validate video data
-	if err := ValidateVideo(&response.Data); err != nil {
+	if err := models.ValidateVideo(video); err != nil {
 		return err
 	}
-	response.Data.VideoID = videoID
+	video.VideoID = videoID
+	video.RefreshedAt = time.Now().UTC().Format(time.RFC3339)
 
 	// Add categories to the video data
-	response.Data.Categories = requestBody.Categories
+	if err := h.createCategories(c.UserContext(), missing); err != nil {
+		return err
+	}
+	video.Categories = requestBody.Categories
+
+	// Link the video to its creator so it shows up on the channel page
+	if video.CreatorDetails.CreatorID == "" {
+		video.CreatorDetails.CreatorID = models.CreatorIDFromChannelLink(video.CreatorDetails.ChannelLink)
+	}
+	if video.CreatorDetails.CreatorID != "" {
+		creator := models.CreatorFromDetails(video.CreatorDetails)
+		if err := h.Creators.InsertOrUpdateCreator(c.UserContext(), &creator); err != nil {
+			lp.FromContext(c.UserContext()).Warn("failed to store creator", "creator_id", creator.CreatorID, "error", err)
+		}
+	}
 
 	// Check if the video already exists in OpenSearch
-	existingVideo, _ := db.GetVideoByID(response.Data.VideoID)
+	existingVideo, err := h.Videos.GetVideoByID(c.UserContext(), video.VideoID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return err
+	}
 
 	// Insert or update the video
 	if existingVideo == nil {
 		// Video does not exist, insert it
-		if err := db.InsertVideo(&response.Data); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		if err := h.Videos.InsertVideo(c.UserContext(), video); err != nil {
+			return err
 		}
 	} else {
 		// Video exists, update it if necessary
-		isChanged, updatedResponse := CompareAndUpdate(existingVideo, &response.Data)
+		isChanged, updatedResponse := models.CompareAndUpdate(c.UserContext(), existingVideo, video)
 		if isChanged {
-			if err := db.UpdateVideo(updatedResponse); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+			if err := h.Videos.UpdateVideo(c.UserContext(), updatedResponse); err != nil {
+				return err
 			}
 		}
 	}
@@ -73,7 +97,7 @@ func InsertOrUpdateVideo(c *fiber.Ctx) error {
 }
 
 // GetVideo retrieves a video by its ID from OpenSearch and returns it in the response
-func GetVideo(c *fiber.Ctx) error {
+func (h *Handler) GetVideo(c *fiber.Ctx) error {
 	// Retrieve the video_id parameter from the request
 	videoID := c.Params("videoId")
 	if videoID == "" {
@@ -81,21 +105,16 @@ func GetVideo(c *fiber.Ctx) error {
 	}
 
 	// Fetch the video details from OpenSearch using the GetVideoByID function
-	video, err := db.GetVideoByID(videoID)
+	video, err := h.Videos.GetVideoByID(c.UserContext(), videoID)
 	if err != nil {
-		// Check if the error is due to the video not being found
-		if err.Error() == "video with ID "+videoID+" not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "youtube video not found"})
-		}
-		// For other errors, return a 500 Internal Server Error response
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "youtube video not found"})
+		return err
 	}
 
 	// Return the video details in the response
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"video": video})
 }
 
-func DeleteVideo(c *fiber.Ctx) error {
+func (h *Handler) DeleteVideo(c *fiber.Ctx) error {
 	// Retrieve the video_id parameter from the request
 	videoID := c.Params("videoId")
 	if videoID == "" {
@@ -103,32 +122,37 @@ func DeleteVideo(c *fiber.Ctx) error {
 	}
 
 	// Delete the video from OpenSearch using the DeleteVideoByID function
-	err := db.DeleteVideoByID(videoID)
-	if err != nil {
-		// Check if the error is due to the video not being found
-		if err.Error() == "video with ID "+videoID+" not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "youtube video not found"})
-		}
-		// For other errors, return a 500 Internal Server Error response
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to delete youtube video"})
+	if err := h.Videos.DeleteVideoByID(c.UserContext(), videoID); err != nil {
+		return err
 	}
 
 	// Return a success message in the response
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "youtube video deleted successfully"})
 }
 
-// SearchVideos searches for videos in the OpenSearch index based on a query parameter with pagination
-
-func SearchVideos(c *fiber.Ctx) error {
+// SearchVideos searches for videos with optional filters and sort order, tolerating typos
+// as configured in search, and returns the page of hits with their highlights and the
+// category and creator facets
+func (h *Handler) SearchVideos(c *fiber.Ctx) error {
 	// Parse request body
 	var req models.SearchVideosRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
 	}
 
-	// Validate the query parameter
-	if req.Query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "query parameter is required"})
+	// Validate the query, filters and sort order
+	if req.Query == "" && req.Filters.IsEmpty() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "query or filters are required"})
+	}
+	if err := req.Filters.Validate(); err != nil {
+		return err
+	}
+	switch req.Sort {
+	case "":
+		req.Sort = models.SortRelevance
+	case models.SortRelevance, models.SortNewest, models.SortMostViewed, models.SortMostLiked:
+	default:
+		return errs.Validation("sort must be one of relevance, newest, most_viewed or most_liked")
 	}
 
 	// Set default pagination parameters if not provided
@@ -138,75 +162,108 @@ func SearchVideos(c *fiber.Ctx) error {
 	if req.Size <= 0 {
 		req.Size = 10
 	}
-
-	// Calculate the starting point for pagination
-	from := (req.Page - 1) * req.Size
+	page, err := selectPage(req.Page, req.Size, req.Cursor, req.PointInTime)
+	if err != nil {
+		return err
+	}
+	if page.Cursor != nil && page.Cursor.Sort != req.Sort {
+		return errs.Validation("cursor belongs to the %s sort order", page.Cursor.Sort)
+	}
 
 	// Perform the search operation in the database
-	total, videos, err := db.SearchVideos(req.Query, from, req.Size)
+	result, err := h.Videos.SearchVideos(c.UserContext(), models.VideoSearch{
+		Query:   req.Query,
+		Filters: req.Filters,
+		Sort:    req.Sort,
+		Page:    page,
+		Match: models.TextMatch{
+			Fuzziness:    config.Cfg.Search.Fuzziness,
+			PrefixLength: config.Cfg.Search.PrefixLength,
+			PhraseBoost:  config.Cfg.Search.PhraseBoost,
+		},
+	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "error searching for videos"})
+		return err
 	}
 
-	// Return the search results with pagination information
+	// Return the search results with pagination information and facets
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"page":   req.Page,
-		"size":   req.Size,
-		"total":  total,
-		"videos": videos,
+		"page":       req.Page,
+		"size":       req.Size,
+		"sort":       req.Sort,
+		"total":      result.Total,
+		"videos":     result.Hits,
+		"facets":     result.Facets,
+		"nextCursor": nextCursor(result.Next),
 	})
 }
 
-func GetBannerVideos(c *fiber.Ctx) error {
-	_, videos, err := db.SearchVideosByCategory("banner", 0, 10)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "requested data not found"})
+// Suggest returns title, creator and category completions of a partially typed query,
+// grouped by type
+func (h *Handler) Suggest(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "q parameter is required"})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"videos": videos,
-	})
-}
+	// Limit the number of suggestions per group
+	size := c.QueryInt("size", 5)
+	if size <= 0 || size > 10 {
+		return errs.Validation("size must be between 1 and 10")
+	}
 
-func GetAllCategories(c *fiber.Ctx) error {
-	categories, err := db.GetAllCategories()
+	suggestions, err := h.Videos.Suggest(c.UserContext(), query, size)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"categories": categories})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"query":       query,
+		"suggestions": suggestions,
+	})
 }
 
-// GetVideosByCategory retrieves videos by a specific category
-func GetVideosByCategory(c *fiber.Ctx) error {
+// GetVideosByCategory retrieves videos by a specific category, and by all its descendants
+// when includeSubcategories is set
+func (h *Handler) GetVideosByCategory(c *fiber.Ctx) error {
 	category := c.Params("category")
 	if category == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "category parameter is required"})
 	}
 
-	// Get pagination parameters
-	page := c.QueryInt("page", 1)
-	size := c.QueryInt("size", 10)
+	// Get pagination parameters, a cursor continues after the page it was returned with
+	page, size := paginationQuery(c)
+	selection, err := selectPage(page, size, c.Query("cursor"), c.QueryBool("pointInTime"))
+	if err != nil {
+		return err
+	}
 
-	// Calculate the starting point for pagination
-	from := (page - 1) * size
+	categories := []string{category}
+	if c.QueryBool("includeSubcategories") {
+		all, err := h.Categories.GetCategories(c.UserContext())
+		if err != nil {
+			return err
+		}
+		categories = models.Subtree(all, category)
+	}
 
-	total, videos, err := db.SearchVideosByCategory(category, from, size)
+	result, err := h.Videos.SearchVideosByCategory(c.UserContext(), categories, selection)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "requested data not found"})
+		return err
 	}
 
 	// Return the search results with pagination information
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"page":   page,
-		"size":   size,
-		"total":  total,
-		"videos": videos,
+		"page":       page,
+		"size":       size,
+		"total":      result.Total,
+		"videos":     result.Videos,
+		"nextCursor": nextCursor(result.Next),
 	})
 }
 
 // RemoveCategoryByID removes a specific category from a video by its ID
-func RemoveCategoryByID(c *fiber.Ctx) error {
+func (h *Handler) RemoveCategoryByID(c *fiber.Ctx) error {
 	videoID := c.Params("video_id")
 	if videoID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "video_id parameter is required"})
@@ -217,140 +274,49 @@ func RemoveCategoryByID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "category parameter is required"})
 	}
 
-	// Fetch the video by ID
-	existingVideo, err := db.GetVideoByID(videoID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "error fetching video"})
-	}
-
-	// Remove the category from the video
-	updatedCategories := []string{}
-	for _, cat := range existingVideo.Categories {
-		if cat != category {
-			updatedCategories = append(updatedCategories, cat)
-		}
-	}
-	existingVideo.Categories = updatedCategories
-
-	if err := db.UpdateVideo(existingVideo); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	// Remove the category from the video in place
+	change := models.CategoryChange{Remove: []string{category}}
+	if _, err := h.Videos.ChangeVideoCategories(c.UserContext(), videoID, change); err != nil {
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "Category removed successfully"})
 }
 
-// NewRequest sends a GET request to the video info endpoint and returns the response body.
-func NewRequest(videoID string) ([]byte, error) {
-	url := fmt.Sprintf("https://yig-video-downloader-backend.vercel.app/get_youtube_video_info?url=https://www.youtube.com/watch?v=%s&details=true", videoID)
+// PatchVideoCategories adds, removes or replaces the categories of a video without
+// fetching its metadata again and returns the resulting categories
+func (h *Handler) PatchVideoCategories(c *fiber.Ctx) error {
+	videoID := c.Params("videoId")
+	if videoID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "video_id parameter is required"})
+	}
 
-	// Send GET request
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
+	var requestBody struct {
+		models.CategoryChange
+		AutoCreateCategories bool `json:"auto_create_categories"`
+	}
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing request body"})
+	}
+	change := requestBody.CategoryChange
+	if err := models.ValidateCategoryChange(change); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
 
-	// Read response body
-	body, err := ioutil.ReadAll(resp.Body)
+	missing, err := h.missingCategories(c.UserContext(), append(append([]string{}, change.Add...), change.Replace...))
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	return body, nil
-}
-
-// ValidateVideo validates the video data
-func ValidateVideo(video *models.Video) error {
-	// Check if video title is empty
-	if video.Title == "" {
-		return errors.New("title is required")
+	if len(missing) > 0 && !requestBody.AutoCreateCategories {
+		return errs.Validation("unknown categories %s, create them first or set auto_create_categories", strings.Join(missing, ", "))
 	}
-
-	// Additional validation rules can be added here
-
-	return nil
-}
-
-// CompareAndUpdate checks if the fields of the current video are different from the provided video
-// and updates the current video with the new values if necessary.
-func CompareAndUpdate(oldVideo, newVideo *models.Video) (bool, *models.Video) {
-	changed := false
-
-	if oldVideo.VideoID != newVideo.VideoID {
-		fmt.Println("Video ID has changed, updating...")
-		oldVideo.VideoID = newVideo.VideoID
-		changed = true
-	}
-	if oldVideo.Title != newVideo.Title {
-		fmt.Println("Title has changed, updating...")
-		oldVideo.Title = newVideo.Title
-		changed = true
-	}
-	// Compare other fields similarly
-	if !compareThumbnails(oldVideo.Thumbnails, newVideo.Thumbnails) {
-		fmt.Println("Thumbnails have changed, updating...")
-		oldVideo.Thumbnails = newVideo.Thumbnails
-		changed = true
-	}
-	if oldVideo.Likes != newVideo.Likes {
-		fmt.Println("Likes count has changed, updating...")
-		oldVideo.Likes = newVideo.Likes
-		changed = true
-	}
-	if oldVideo.ViewsCount != newVideo.ViewsCount {
-		fmt.Println("Views count has changed, updating...")
-		oldVideo.ViewsCount = newVideo.ViewsCount
-		changed = true
-	}
-	// Add comparisons for other fields as needed
-	if oldVideo.UploadDate != newVideo.UploadDate {
-		fmt.Println("Upload date has changed, updating...")
-		oldVideo.UploadDate = newVideo.UploadDate
-		changed = true
-	}
-	if oldVideo.VideoCategory != newVideo.VideoCategory {
-		fmt.Println("Video category has changed, updating...")
-		oldVideo.VideoCategory = newVideo.VideoCategory
-		changed = true
-	}
-	if oldVideo.Description != newVideo.Description {
-		fmt.Println("Description has changed, updating...")
-		oldVideo.Description = newVideo.Description
-		changed = true
-	}
-	if oldVideo.Dislikes != newVideo.Dislikes {
-		fmt.Println("Dislikes count has changed, updating...")
-		oldVideo.Dislikes = newVideo.Dislikes
-		changed = true
-	}
-	if oldVideo.IsShort != newVideo.IsShort {
-		fmt.Println("IsShort flag has changed, updating...")
-		oldVideo.IsShort = newVideo.IsShort
-		changed = true
-	}
-	if oldVideo.CreatorDetails != newVideo.CreatorDetails {
-		fmt.Println("Creator details have changed, updating...")
-		oldVideo.CreatorDetails = newVideo.CreatorDetails
-		changed = true
-	}
-	if oldVideo.LastUpdated != newVideo.LastUpdated {
-		fmt.Println("Last updated timestamp has changed, updating...")
-		oldVideo.LastUpdated = newVideo.LastUpdated
-		changed = true
-	}
-
-	return changed, oldVideo
-}
-
-// compareThumbnails compares two slices of Thumbnail and returns true if they are equal, false otherwise.
-func compareThumbnails(a, b []models.Thumbnail) bool {
-	if len(a) != len(b) {
-		return false
+	if err := h.createCategories(c.UserContext(), missing); err != nil {
+		return err
 	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
-		}
+
+	categories, err := h.Videos.ChangeVideoCategories(c.UserContext(), videoID, change)
+	if err != nil {
+		return err
 	}
-	return true
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"videoId": videoID, "categories": categories})
 }
@@ -2,30 +2,54 @@ package router
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/shaik80/ODIW/internal/auth"
 	"github.com/shaik80/ODIW/internal/server/api/handler"
 )
 
-// Setup initializes and returns the Fiber app with defined routes
-func SetupRoutes(app *fiber.App) *fiber.App {
-	// Initialize Handler
-	// register Handlers
-
-	// routers
-	app.Get("/api/youtube/banner", handler.GetBannerVideos)
-	app.Get("/api/youtube/categories", handler.GetAllCategories)
-	app.Get("/api/youtube/videos/category/:category", handler.GetVideosByCategory)
-	app.Delete("/api/youtube/videos/:video_id/category", handler.RemoveCategoryByID)
-
-	app.Post("/api/youtube/video", handler.InsertOrUpdateVideo)
-	app.Get("/api/youtube/video/:videoId", handler.GetVideo)
-	app.Delete("/api/youtube/video/:videoId", handler.DeleteVideo)
-	app.Post("/api/youtube/search", handler.SearchVideos)
-
-	// Creator Routes
-	app.Post("/api/youtube/creator", handler.InsertOrUpdateCreator)
-	app.Get("/api/youtube/creator/:creatorId", handler.GetCreator)
-	app.Get("/api/youtube/creators", handler.GetCreators)
-	app.Get("/api/youtube/creator/:creatorId/videos", handler.GetVideosByCreator)
+// Setup initializes and returns the Fiber app with defined routes.
+// Read routes are public, changes need a curator and deletions an admin.
+func SetupRoutes(app *fiber.App, handler *handler.Handler, authn *auth.Authenticator) *fiber.App {
+	api := app.Group("/api/youtube")
+
+	// Role middleware is attached per route: fiber group middleware applies to every
+	// route sharing the prefix, not only the ones registered on the group.
+	curator := authn.Require(auth.RoleCurator)
+	admin := authn.Require(auth.RoleAdmin)
+
+	// Public routes
+	api.Get("/banner", handler.GetBanners)
+	api.Get("/categories", handler.GetCategories)
+	api.Get("/categories/:slug", handler.GetCategory)
+	api.Get("/videos/category/:category", handler.GetVideosByCategory)
+	api.Get("/video/:videoId", handler.GetVideo)
+	api.Post("/search", handler.SearchVideos)
+	api.Get("/search/suggest", handler.Suggest)
+	api.Get("/creator/:creatorId", handler.GetCreator)
+	api.Get("/creators", handler.GetCreators)
+	api.Get("/creator/:creatorId/videos", handler.GetVideosByCreator)
+
+	// Curator routes
+	api.Post("/video", curator, handler.InsertOrUpdateVideo)
+	api.Post("/videos/bulk", curator, handler.BulkImportVideos)
+	api.Delete("/videos/:video_id/category", curator, handler.RemoveCategoryByID)
+	api.Patch("/video/:videoId/categories", curator, handler.PatchVideoCategories)
+	api.Post("/creator", curator, handler.InsertOrUpdateCreator)
+	api.Post("/categories", curator, handler.CreateCategory)
+	api.Put("/categories/:slug", curator, handler.UpdateCategory)
+	api.Put("/categories/:slug/parent", curator, handler.MoveCategory)
+
+	// Admin routes
+	api.Delete("/video/:videoId", admin, handler.DeleteVideo)
+	api.Delete("/categories/:slug", admin, handler.DeleteCategory)
+	api.Post("/categories/:slug/rename", admin, handler.RenameCategory)
+	api.Post("/categories/:slug/merge", admin, handler.MergeCategory)
+	api.Post("/videos/categories", admin, handler.UpdateVideoCategories)
+	api.Get("/categories/tasks/:taskId", admin, handler.GetCategoryTask)
+	api.Get("/banners", admin, handler.GetAllBanners)
+	api.Get("/banners/:bannerId", admin, handler.GetBanner)
+	api.Post("/banners", admin, handler.CreateBanner)
+	api.Put("/banners/:bannerId", admin, handler.UpdateBanner)
+	api.Delete("/banners/:bannerId", admin, handler.DeleteBanner)
 
 	app.Get("/s", func(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"Hello": "world"})
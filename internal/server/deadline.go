@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestDeadline gives the user context of every request a deadline of timeout, or of
+// the one routeTimeouts lists for its method and path such as "POST /api/youtube/videos/bulk",
+// zero meaning none, and cancels it when base is cancelled. Handlers pass c.UserContext()
+// to the repositories and the metadata provider, so their calls stop with the request.
+// fasthttp does not report client disconnects, so abandoned requests run until the
+// deadline. It must run after requestLogger to keep the request logger.
+func requestDeadline(base context.Context, timeout time.Duration, routeTimeouts map[string]time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		timeout := timeout
+		if routeTimeout, ok := routeTimeouts[c.Method()+" "+c.Path()]; ok {
+			timeout = routeTimeout
+		}
+
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(c.UserContext(), timeout)
+		} else {
+			ctx, cancel = context.WithCancel(c.UserContext())
+		}
+		defer cancel()
+		stop := context.AfterFunc(base, cancel)
+		defer stop()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shaik80/ODIW/internal/health"
+	"github.com/shaik80/ODIW/internal/metadata"
+)
+
+const readinessTimeout = 3 * time.Second
+
+// setupHealth registers the liveness and readiness probes
+func setupHealth(app *fiber.App, provider metadata.VideoMetadataProvider) {
+	checker := &health.Checker{
+		Timeout: readinessTimeout,
+		Checks: []health.Check{
+			health.OpenSearchCheck(storageReady.Load),
+			health.IndicesCheck(storageReady.Load),
+			health.MetadataCheck(provider),
+		},
+	}
+
+	// healthz only reports that the process is serving requests
+	app.Get("/healthz", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": health.StatusOK})
+	})
+
+	// readyz answers 503 while a critical dependency is unavailable
+	app.Get("/readyz", func(c *fiber.Ctx) error {
+		report := checker.Run(c.UserContext())
+		status := fiber.StatusOK
+		if !report.Ready() {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(report)
+	})
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// requestLogger stores a logger tagged with the request ID in the request context, available
+// through lp.FromContext(c.UserContext()), and writes one access log event per request.
+// It must run after the requestid middleware.
+func requestLogger(c *fiber.Ctx) error {
+	start := time.Now()
+	requestID, _ := c.Locals("requestid").(string)
+
+	logger := lp.Logs.With("request_id", requestID)
+	c.SetUserContext(lp.WithContext(c.UserContext(), logger))
+
+	// Render errors here so the logged status matches the response
+	if err := c.Next(); err != nil {
+		if err := c.App().ErrorHandler(c, err); err != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	logger.Info("request",
+		"method", c.Method(),
+		"path", c.Path(),
+		"status", c.Response().StatusCode(),
+		"latency_ms", time.Since(start).Milliseconds(),
+		"ip", c.IP(),
+	)
+	return nil
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/shaik80/ODIW/config"
+	"github.com/shaik80/ODIW/internal/auth"
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/metrics"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// statsTimeout bounds the storage query run on every scrape
+const statsTimeout = 5 * time.Second
+
+// metricsMiddleware records the duration of every request by route pattern and status.
+// It runs outside the request logger, which has already rendered handler errors.
+func metricsMiddleware(c *fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	// When no route matched, the last route seen is the global middleware mounted at "/"
+	route := c.Route().Path
+	if route == "/" {
+		route = "unmatched"
+	}
+	metrics.ObserveHTTP(c.Method(), route, c.Response().StatusCode(), time.Since(start))
+	return err
+}
+
+// updateVideoGauges sets the video gauges from storage before a scrape
+func updateVideoGauges(ctx context.Context, videos repository.VideoRepository) {
+	if !storageReady.Load() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, statsTimeout)
+	defer cancel()
+
+	stats, err := videos.GetVideoStats(ctx)
+	if err != nil {
+		lp.FromContext(ctx).Warn("collecting video stats failed", "error", err)
+		return
+	}
+	metrics.VideosTotal.Set(float64(stats.Total))
+	metrics.CategoryVideos.Reset()
+	for _, category := range stats.Categories {
+		metrics.CategoryVideos.WithLabelValues(category.Name).Set(float64(category.Count))
+	}
+}
+
+// setupMetrics serves the metrics registry and keeps the video gauges up to date
+func setupMetrics(app *fiber.App, cfg config.MetricsConfig, authn *auth.Authenticator, videos repository.VideoRepository) {
+	handlers := []fiber.Handler{}
+	if cfg.Role != "" {
+		role, ok := auth.ParseRole(cfg.Role)
+		if !ok {
+			lp.Logs.Warn("unknown metrics role, requiring admin", "role", cfg.Role)
+			role = auth.RoleAdmin
+		}
+		handlers = append(handlers, authn.Require(role))
+	}
+	scrape := adaptor.HTTPHandler(metrics.Handler())
+	handlers = append(handlers, func(c *fiber.Ctx) error {
+		updateVideoGauges(c.UserContext(), videos)
+		return scrape(c)
+	})
+
+	path := cfg.Path
+	if path == "" {
+		path = "/metrics"
+	}
+	app.Get(path, handlers...)
+}
@@ -1,43 +1,146 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/shaik80/ODIW/config"
+	"github.com/shaik80/ODIW/internal/auth"
+	"github.com/shaik80/ODIW/internal/db/repository"
+	"github.com/shaik80/ODIW/internal/metadata"
+	"github.com/shaik80/ODIW/internal/refresh"
+	"github.com/shaik80/ODIW/internal/server/api/handler"
 	"github.com/shaik80/ODIW/internal/server/api/router"
+	lp "github.com/shaik80/ODIW/utils/logger"
 )
 
+const defaultShutdownTimeout = 15 * time.Second
+
+// SetupGofiber serves the API until SIGINT or SIGTERM, then drains in-flight requests
+// and stops the background work before returning.
 func SetupGofiber() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// requests is cancelled once draining gives up, aborting the calls still in flight
+	requests, abort := context.WithCancel(context.Background())
+	defer abort()
+
 	// Create a new Fiber instance
-	app := fiber.New()
-	useCustomLoggerMiddleware(app)
+	cfg := config.Cfg.Server
+	app := fiber.New(fiber.Config{
+		ErrorHandler: handler.ErrorHandler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+		BodyLimit:    cfg.BodyLimit,
+		Prefork:      cfg.Prefork,
+		Concurrency:  cfg.Concurrency,
+	})
+	app.Use(requestid.New())
+	if config.Cfg.Metrics.Enabled {
+		app.Use(metricsMiddleware)
+	}
+	app.Use(requestLogger)
+	// Bulk imports fetch the metadata of up to import.max_items videos
+	app.Use(requestDeadline(requests, cfg.RequestTimeout, map[string]time.Duration{
+		fiber.MethodPost + " /api/youtube/videos/bulk": config.Cfg.Import.Timeout,
+	}))
+	app.Use(corsMiddleware)
+
+	provider, err := metadata.NewProvider(config.Cfg.Metadata)
+	if err != nil {
+		lp.Logs.Error("failed to set up metadata provider", "error", err)
+		os.Exit(1)
+	}
+
+	authn, err := auth.New(config.Cfg.Auth)
+	if err != nil {
+		lp.Logs.Error("failed to set up authentication", "error", err)
+		os.Exit(1)
+	}
+
+	videos := repository.NewOpenSearchVideoRepository(config.Cfg.OpenSearch.Timeouts)
+	creators := repository.NewOpenSearchCreatorRepository(config.Cfg.OpenSearch.Timeouts)
+	categories := repository.NewOpenSearchCategoryRepository(config.Cfg.OpenSearch.Timeouts)
+	banners := repository.NewOpenSearchBannerRepository(config.Cfg.OpenSearch.Timeouts)
+
+	// Initialize handlers with the OpenSearch backed repositories
+	h := handler.New(videos, creators, categories, banners, provider)
+
+	// Keep stored metadata fresh in the background once the storage is ready
+	var scheduler *refresh.Scheduler
+	if cfg := config.Cfg.Refresh; cfg.Enabled {
+		scheduler = &refresh.Scheduler{
+			Metadata:    provider,
+			Videos:      videos,
+			Creators:    creators,
+			Interval:    cfg.Interval,
+			BatchSize:   cfg.BatchSize,
+			Concurrency: cfg.Concurrency,
+			MinAge:      cfg.MinAge,
+		}
+	}
+
+	// Serve the probes right away and the API once OpenSearch is reachable. With prefork
+	// only the parent process migrates the indices and refreshes metadata.
+	primary := !fiber.IsChild()
+	go connectStorage(ctx, primary, func() {
+		if scheduler != nil && primary {
+			scheduler.Start()
+		}
+	})
+	setupHealth(app, provider)
+	app.Use("/api", requireStorage)
 
 	// Setup routes
-	router.SetupRoutes(app)
-	// Start the server on port 3000
-	log.Fatal(app.Listen(fmt.Sprint(":", config.Cfg.Server.Port)))
-}
+	router.SetupRoutes(app, h, authn)
+	if config.Cfg.Metrics.Enabled {
+		setupMetrics(app, config.Cfg.Metrics, authn, videos)
+	}
+
+	// Start the server on the configured port
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(fmt.Sprint(":", cfg.Port))
+	}()
 
-func useCustomLoggerMiddleware(app *fiber.App) {
-	// Define custom logger format and colors
-	loggerConfig := logger.Config{
-		Format:     "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${error}\n",
-		TimeFormat: "2006-01-02 15:04:05", // Human-readable time format
-		TimeZone:   "Local",
-		Output:     os.Stdout,
+	select {
+	case err := <-listenErr:
+		if err != nil {
+			lp.Logs.Error("server stopped", "error", err)
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+		shutdown(app, h, scheduler, cfg.ShutdownTimeout, abort)
 	}
+}
 
-	// Create a custom logger middleware
-	customLogger := logger.New(loggerConfig)
+// shutdown stops accepting connections, waits up to timeout for in-flight requests and
+// aborts the rest, stops the background work of the handlers and the refresh scheduler
+// and flushes the logs
+func shutdown(app *fiber.App, h *handler.Handler, scheduler *refresh.Scheduler, timeout time.Duration, abort context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	lp.Logs.Info("shutting down", "timeout", timeout.String())
 
-	// Use the custom logger middleware
-	app.Use(customLogger)
-	app.Use(corsMiddleware)
+	if err := app.ShutdownWithTimeout(timeout); err != nil {
+		lp.Logs.Error("draining connections failed", "error", err)
+	}
+	abort()
+	h.Shutdown()
+	if scheduler != nil {
+		scheduler.Stop()
+	}
 
+	lp.Logs.Info("server stopped")
+	_ = lp.Sync()
 }
 
 // corsMiddleware adds CORS headers to the response
@@ -45,8 +148,8 @@ func corsMiddleware(c *fiber.Ctx) error {
 	c.Set("Access-Control-Allow-Origin", "*") // Allow all origins
 
 	// Optional CORS headers
-	c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+	c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Request-ID")
 
 	// Handle preflight requests
 	if c.Method() == fiber.MethodOptions {
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shaik80/ODIW/config"
+	connect "github.com/shaik80/ODIW/internal/db/opensearch"
+	"github.com/shaik80/ODIW/internal/db/opensearch/schema"
+	"github.com/shaik80/ODIW/internal/errs"
+	lp "github.com/shaik80/ODIW/utils/logger"
+)
+
+// storageReady is set once the OpenSearch client is initialized and the indices are
+// migrated. connect.Client must not be used by request handlers before then.
+var storageReady atomic.Bool
+
+// connectStorage waits until the OpenSearch cluster is available or ctx is done,
+// applies the index migrations when migrate is set and then calls onReady. An invalid
+// client configuration, a missing synonyms file or a failed migration stops the process.
+//
+// The API answers 503 until onReady, reads included. With auto_migrate that lasts for
+// the whole reindex of every index whose version changed, so upgrade large indices with
+// the migrate command before starting the new version instead.
+func connectStorage(ctx context.Context, migrate bool, onReady func()) {
+	client, err := connect.NewClient(config.Cfg.OpenSearch)
+	if err != nil {
+		lp.Logs.Error("failed to create OpenSearch client", "error", err)
+		os.Exit(1)
+	}
+	if err := connect.WaitForCluster(ctx, client); err != nil {
+		return
+	}
+	connect.Client = client
+
+	// Create or upgrade the indices before serving traffic
+	if migrate && config.Cfg.OpenSearch.AutoMigrate {
+		if err := schema.Migrate(ctx, connect.Client, schema.MigrateOptions{}); err != nil {
+			lp.Logs.Error("failed to migrate indices", "error", err)
+			os.Exit(1)
+		}
+	} else if err := schema.CheckSynonyms(ctx, connect.Client); err != nil {
+		lp.Logs.Error("search analyzer is not usable", "error", err)
+		os.Exit(1)
+	}
+
+	storageReady.Store(true)
+	lp.Logs.Info("storage is ready")
+	onReady()
+}
+
+// requireStorage answers 503 until the storage is ready
+func requireStorage(c *fiber.Ctx) error {
+	if !storageReady.Load() {
+		return errs.Storage(nil, "OpenSearch is not ready yet")
+	}
+	return c.Next()
+}
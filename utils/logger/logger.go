@@ -1,13 +1,18 @@
 package loggger
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
 )
 
 // LogLevel represents the log level.
 type LogLevel int
 
-var Logs Logger
+var Logs Logger = New(Info, FormatText, os.Stderr)
 
 const (
 	Debug LogLevel = iota
@@ -16,43 +21,151 @@ const (
 	Error
 )
 
-// Logger defines the interface for logging.
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Logger defines the interface for logging. The printf style methods are kept for
+// existing callers; new code should prefer the key/value methods.
 type Logger interface {
 	Debugf(format string, args ...interface{})
 	Infof(format string, args ...interface{})
 	Warnf(format string, args ...interface{})
 	Errorf(format string, args ...interface{})
+
+	// Debug, Info, Warn and Error log msg with alternating key/value pairs
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+
+	// With returns a logger adding the key/value pairs to every event
+	With(keyvals ...interface{}) Logger
+}
+
+// ParseLevel maps a level name to a LogLevel
+func ParseLevel(name string) (LogLevel, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return Debug, nil
+	case "info", "":
+		return Info, nil
+	case "warn", "warning":
+		return Warn, nil
+	case "error":
+		return Error, nil
+	}
+	return Info, fmt.Errorf("invalid log level: %s", name)
 }
 
 // ConfigurableLogger logs based on configuration.
 type ConfigurableLogger struct {
 	LogLevel LogLevel
+	logger   *slog.Logger
+	out      io.Writer
+}
+
+// New returns a logger writing events at or above level to w as text or JSON
+func New(level LogLevel, format string, w io.Writer) ConfigurableLogger {
+	opts := &slog.HandlerOptions{Level: slogLevel(level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(format, FormatJSON) {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return ConfigurableLogger{LogLevel: level, logger: slog.New(handler), out: w}
+}
+
+// Sync flushes the output when it is a file. Events are written unbuffered, so this
+// only matters for file systems that delay writes.
+func (l ConfigurableLogger) Sync() error {
+	if syncer, ok := l.out.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Sync flushes Logs if it supports flushing
+func Sync() error {
+	if syncer, ok := Logs.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
 }
 
 // Debugf logs a debug message based on log level.
 func (l ConfigurableLogger) Debugf(format string, args ...interface{}) {
-	if l.LogLevel <= Debug {
-		log.Printf("[DEBUG] "+format, args...)
-	}
+	l.log(Debug, fmt.Sprintf(format, args...))
 }
 
 // Infof logs an info message based on log level.
 func (l ConfigurableLogger) Infof(format string, args ...interface{}) {
-	if l.LogLevel <= Info {
-		log.Printf("[INFO] "+format, args...)
-	}
+	l.log(Info, fmt.Sprintf(format, args...))
 }
 
 // Warnf logs a warning message based on log level.
 func (l ConfigurableLogger) Warnf(format string, args ...interface{}) {
-	if l.LogLevel <= Warn {
-		log.Printf("[WARN] "+format, args...)
-	}
+	l.log(Warn, fmt.Sprintf(format, args...))
 }
 
 // Errorf logs an error message based on log level.
 func (l ConfigurableLogger) Errorf(format string, args ...interface{}) {
-	if l.LogLevel <= Error {
-		log.Printf("[ERROR] "+format, args...)
+	l.log(Error, fmt.Sprintf(format, args...))
+}
+
+// Debug logs a debug event with key/value pairs.
+func (l ConfigurableLogger) Debug(msg string, keyvals ...interface{}) {
+	l.log(Debug, msg, keyvals...)
+}
+
+// Info logs an info event with key/value pairs.
+func (l ConfigurableLogger) Info(msg string, keyvals ...interface{}) {
+	l.log(Info, msg, keyvals...)
+}
+
+// Warn logs a warning event with key/value pairs.
+func (l ConfigurableLogger) Warn(msg string, keyvals ...interface{}) {
+	l.log(Warn, msg, keyvals...)
+}
+
+// Error logs an error event with key/value pairs.
+func (l ConfigurableLogger) Error(msg string, keyvals ...interface{}) {
+	l.log(Error, msg, keyvals...)
+}
+
+// With returns a logger adding the key/value pairs to every event.
+func (l ConfigurableLogger) With(keyvals ...interface{}) Logger {
+	l.logger = l.slog().With(keyvals...)
+	return l
+}
+
+func (l ConfigurableLogger) log(level LogLevel, msg string, keyvals ...interface{}) {
+	if level < l.LogLevel {
+		return
+	}
+	l.slog().Log(context.Background(), slogLevel(level), msg, keyvals...)
+}
+
+// slog falls back to the default slog logger for a zero ConfigurableLogger
+func (l ConfigurableLogger) slog() *slog.Logger {
+	if l.logger == nil {
+		return slog.Default()
+	}
+	return l.logger
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case Debug:
+		return slog.LevelDebug
+	case Warn:
+		return slog.LevelWarn
+	case Error:
+		return slog.LevelError
 	}
+	return slog.LevelInfo
 }